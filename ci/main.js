@@ -1,4 +1,5 @@
 const GO_LIVE_CONNECTED = "go-live-connected";
+const GO_LIVE_SCANNED = "go-live-scanned";
 const GO_LIVE_COMPONENT_ID = "go-live-component-id";
 const EVENT_LIVE_DOM_COMPONENT_ID_KEY = "cid";
 const EVENT_LIVE_DOM_INSTRUCTIONS_KEY = "i";
@@ -7,6 +8,25 @@ const EVENT_LIVE_DOM_CONTENT_KEY = "c";
 const EVENT_LIVE_DOM_ATTR_KEY = "a";
 const EVENT_LIVE_DOM_SELECTOR_KEY = "s";
 const EVENT_LIVE_DOM_INDEX_KEY = "i";
+const RECONNECT_BASE_DELAY = 250;
+const RECONNECT_MAX_DELAY = 10000;
+const TRANSPORT_WEBSOCKET = "ws";
+const TRANSPORT_SSE = "sse";
+const UPLOAD_CHUNK_SIZE = 64 * 1024;
+const EVENT_BINDING_PREFIX = "go-live-on:";
+// Properties of the events sent within the dom event
+const DOM_EVENT_PROPERTIES = [
+    "key",
+    "altKey",
+    "ctrlKey",
+    "metaKey",
+    "shiftKey",
+    "button",
+    "clientX",
+    "clientY",
+    "deltaX",
+    "deltaY",
+];
 
 const handleChange = {
     "{{ .Enum.DiffSetAttr }}": handleDiffSetAttr,
@@ -16,10 +36,19 @@ const handleChange = {
     "{{ .Enum.DiffSetInnerHTML }}": handleDiffSetInnerHTML,
     "{{ .Enum.DiffAppend }}": handleDiffAppend,
     "{{ .Enum.DiffMove }}": handleDiffMove,
+    "{{ .Enum.DiffInsert }}": handleDiffInsert,
 };
 
 const goLive = {
-    server: createConnection(),
+    server: null,
+
+    transport: TRANSPORT_WEBSOCKET,
+    websocketOpened: false,
+
+    reconnectAttempts: 0,
+
+    // Path and query of the live page shown
+    path: window.location.pathname + window.location.search,
 
     handlers: [],
 
@@ -54,9 +83,136 @@ const goLive = {
     },
 
     send(message) {
+        if (!goLive.server || goLive.server.readyState !== WebSocket.OPEN) {
+            console.warn("connection not open, message dropped", message);
+            return;
+        }
+
         goLive.server.send(JSON.stringify(message));
     },
 
+    navigate(url) {
+        // Without the connection, the page is loaded as usual
+        if (!goLive.server || goLive.server.readyState !== WebSocket.OPEN) {
+            window.location.assign(url);
+            return;
+        }
+
+        goLive.send({
+            name: "{{ .Enum.EventLiveNavigate }}",
+            url: url,
+        });
+    },
+
+    upload(componentId, element, file) {
+        const id = [
+            Date.now().toString(36),
+            Math.random().toString(36).substring(2),
+        ].join("");
+
+        goLive.send({
+            name: "{{ .Enum.EventLiveUpload }}",
+            component_id: componentId,
+            method_name: element.getAttribute("go-live-upload"),
+            method_data: dataFromElementAttributes(element),
+            upload: { id: id, name: file.name, type: file.type, size: file.size },
+        });
+
+        const sendChunk = (offset) => {
+            if (offset >= file.size) {
+                return;
+            }
+
+            const reader = new FileReader();
+            reader.onload = () => {
+                // Data URL content after "data:<type>;base64,"
+                const data = reader.result.substring(reader.result.indexOf(",") + 1);
+
+                goLive.send({
+                    name: "{{ .Enum.EventLiveUploadChunk }}",
+                    component_id: componentId,
+                    upload: { id: id, offset: offset, data: data },
+                });
+
+                sendChunk(offset + UPLOAD_CHUNK_SIZE);
+            };
+            reader.readAsDataURL(file.slice(offset, offset + UPLOAD_CHUNK_SIZE));
+        };
+
+        sendChunk(0);
+    },
+
+    connectServer() {
+        const server =
+            goLive.transport === TRANSPORT_SSE
+                ? createSSEConnection()
+                : createConnection();
+        let opened = false;
+
+        server.onmessage = (rawMessage) => {
+            try {
+                const message = JSON.parse(rawMessage.data);
+                goLive.emit(message.t, message);
+            } catch (e) {
+                console.log("Error", e);
+                console.log("Error message", rawMessage.data);
+            }
+        };
+
+        server.onopen = () => {
+            opened = true;
+            goLive.reconnectAttempts = 0;
+            goLive.websocketOpened =
+                goLive.websocketOpened || goLive.transport === TRANSPORT_WEBSOCKET;
+            goLive.once.emit("WS_CONNECTION_OPEN");
+        };
+
+        server.onclose = () => {
+            // Websocket upgrades stripped by a proxy never open while the
+            // server answers HTTP, falling back to Server-Sent Events. A
+            // server down, as while restarting, is reconnected.
+            if (
+                !opened &&
+                !goLive.websocketOpened &&
+                goLive.transport === TRANSPORT_WEBSOCKET
+            ) {
+                probeHTTP().then((reachable) => {
+                    if (!reachable) {
+                        goLive.reconnectServer();
+                        return;
+                    }
+
+                    console.warn("websocket unavailable, falling back to sse");
+                    goLive.transport = TRANSPORT_SSE;
+                    goLive.connectServer();
+                });
+                return;
+            }
+
+            // Server-Sent Events failing before opening, the websocket is
+            // tried again
+            if (!opened && goLive.transport === TRANSPORT_SSE) {
+                console.warn("sse unavailable, retrying websocket");
+                goLive.transport = TRANSPORT_WEBSOCKET;
+            }
+
+            goLive.reconnectServer();
+        };
+
+        goLive.server = server;
+    },
+
+    reconnectServer() {
+        const delay = Math.min(
+            RECONNECT_MAX_DELAY,
+            RECONNECT_BASE_DELAY * Math.pow(2, goLive.reconnectAttempts)
+        );
+
+        goLive.reconnectAttempts++;
+
+        setTimeout(() => goLive.connectServer(), delay);
+    },
+
     connectChildren(viewElement) {
         const liveChildren = viewElement.querySelectorAll(
             "*[" + GO_LIVE_COMPONENT_ID + "]"
@@ -84,9 +240,10 @@ const goLive = {
         clickElements.forEach(function (element) {
 
             const componentId = getComponentIdFromElement(element);
+            const send = rateLimitedSend(element);
 
             element.addEventListener("click", function (_) {
-                goLive.send({
+                send({
                     name: "{{ .Enum.EventLiveMethod }}",
                     component_id: componentId,
                     method_name: element.getAttribute("go-live-click"),
@@ -97,11 +254,52 @@ const goLive = {
             connectedElements.push(element)
         });
 
+        const pollElements = findLivePollsFromElement(viewElement);
+        pollElements.forEach(function (element) {
+
+            const componentId = getComponentIdFromElement(element);
+            const interval = parseInt(element.getAttribute("go-live-poll-interval"), 10) || 1000;
+
+            // Polls only while the element is in the viewport
+            let visible = true;
+            let observer = null;
+
+            if ("IntersectionObserver" in window) {
+                visible = false;
+                observer = new IntersectionObserver((entries) => {
+                    visible = entries[entries.length - 1].isIntersecting;
+                });
+                observer.observe(element);
+            }
+
+            const timer = setInterval(function () {
+                if (!element.isConnected) {
+                    clearInterval(timer);
+                    observer && observer.disconnect();
+                    return;
+                }
+
+                if (!visible || document.visibilityState !== "visible") {
+                    return;
+                }
+
+                goLive.send({
+                    name: "{{ .Enum.EventLiveMethod }}",
+                    component_id: componentId,
+                    method_name: element.getAttribute("go-live-poll"),
+                    method_data: dataFromElementAttributes(element),
+                });
+            }, interval);
+
+            connectedElements.push(element)
+        });
+
         const keydownElements = findLiveKeyDownFromElement(viewElement);
         keydownElements.forEach(function (element) {
 
             const componentId = getComponentIdFromElement(element);
             const method = element.getAttribute("go-live-keydown");
+            const send = rateLimitedSend(element);
 
             const attrs = element.attributes;
             let filterKeys = [];
@@ -130,7 +328,7 @@ const goLive = {
                 }
 
                 if (hit) {
-                    goLive.send({
+                    send({
                         name: "{{ .Enum.EventLiveMethod }}",
                         component_id: componentId,
                         method_name: method,
@@ -145,20 +343,130 @@ const goLive = {
             connectedElements.push(element)
         });
 
+        const submitElements = findLiveSubmitsFromElement(viewElement);
+        submitElements.forEach(function (element) {
+
+            const componentId = getComponentIdFromElement(element);
+
+            element.addEventListener("submit", function (event) {
+                event.preventDefault();
+
+                goLive.send({
+                    name: "{{ .Enum.EventLiveSubmit }}",
+                    component_id: componentId,
+                    method_name: element.getAttribute("go-live-submit"),
+                    method_data: dataFromElementAttributes(element),
+                    form_data: dataFromForm(element),
+                });
+            });
+
+            connectedElements.push(element)
+        });
+
+        const uploadElements = findLiveUploadsFromElement(viewElement);
+        uploadElements.forEach(function (element) {
+
+            const componentId = getComponentIdFromElement(element);
+
+            element.addEventListener("change", function (_) {
+                for (const file of element.files) {
+                    goLive.upload(componentId, element, file);
+                }
+
+                // The same file can be selected again
+                element.value = "";
+            });
+
+            connectedElements.push(element)
+        });
+
+        const linkElements = findLiveLinksFromElement(viewElement);
+        linkElements.forEach(function (element) {
+
+            element.addEventListener("click", function (event) {
+                if (
+                    event.defaultPrevented ||
+                    event.button !== 0 ||
+                    event.metaKey ||
+                    event.ctrlKey ||
+                    event.shiftKey ||
+                    event.altKey
+                ) {
+                    return;
+                }
+
+                const url = new URL(element.href, window.location.href);
+                if (url.origin !== window.location.origin) {
+                    return;
+                }
+
+                event.preventDefault();
+                goLive.navigate(url.pathname + url.search + url.hash);
+            });
+
+            connectedElements.push(element)
+        });
+
+        const boundElements = findLiveEventBindingsFromElement(viewElement);
+        boundElements.forEach(function (element) {
+
+            const componentId = getComponentIdFromElement(element);
+            const send = rateLimitedSend(element);
+            const prevent = element.hasAttribute("go-live-prevent");
+
+            for (const attr of element.attributes) {
+                if (!attr.name.startsWith(EVENT_BINDING_PREFIX)) {
+                    continue;
+                }
+
+                const eventName = attr.name.substring(EVENT_BINDING_PREFIX.length);
+                const method = attr.value;
+
+                // Drops and submits are handled by the server, the browser
+                // would open the file or reload the page
+                const preventDefault = prevent || eventName === "drop" || eventName === "submit";
+
+                // Elements only become drop targets when dragover is prevented
+                if (eventName === "drop") {
+                    element.addEventListener("dragover", (event) => event.preventDefault());
+                }
+
+                element.addEventListener(eventName, function (event) {
+                    if (preventDefault) {
+                        event.preventDefault();
+                    }
+
+                    send({
+                        name: "{{ .Enum.EventLiveMethod }}",
+                        component_id: componentId,
+                        method_name: method,
+                        method_data: dataFromElementAttributes(element),
+                        dom_event: domEventFromEvent(event, element),
+                    });
+                }, { passive: !preventDefault });
+            }
+
+            connectedElements.push(element)
+        });
+
         const liveInputs = findLiveInputsFromElement(viewElement);
         liveInputs.forEach(function (element) {
 
             const type = element.getAttribute("type");
             const componentId = getComponentIdFromElement(element);
+            const send = rateLimitedSend(element);
+
+            // Lazy inputs are sent when changed, on blur or enter
+            const eventName = element.hasAttribute("go-live-lazy") ? "change" : "input";
 
-            element.addEventListener("input", function (_) {
+            element.addEventListener(eventName, function (_) {
                 let value = element.value;
 
                 if (type === "checkbox") {
                     value = element.checked;
                 }
 
-                goLive.send({
+                send({
                     name: "{{ .Enum.EventLiveInput }}",
                     component_id: componentId,
                     key: element.getAttribute("go-live-input"),
@@ -221,6 +529,14 @@ goLive.once.on("WS_CONNECTION_OPEN", () => {
         const cid = message[EVENT_LIVE_DOM_COMPONENT_ID_KEY];
         goLive.connect(cid);
     });
+    goLive.on("{{ .Enum.EventLiveBatch }}", (message) => {
+        // Patches rendered in the same frame, applied in order
+        for (const patch of message.p || []) {
+            goLive.emit(patch.t, patch);
+        }
+    });
+    goLive.on("{{ .Enum.EventLiveNavigate }}", handleNavigate);
+    goLive.on("{{ .Enum.EventLiveQuery }}", handleQuery);
     goLive.on("{{ .Enum.EventLiveError }}", (message) => {
         console.error("message", message.m)
         if (
@@ -232,19 +548,18 @@ goLive.once.on("WS_CONNECTION_OPEN", () => {
     });
 });
 
-goLive.server.onmessage = (rawMessage) => {
-    try {
-        const message = JSON.parse(rawMessage.data);
-        goLive.emit(message.t, message);
-    } catch (e) {
-        console.log("Error", e);
-        console.log("Error message", rawMessage.data);
+window.addEventListener("popstate", function (_) {
+    const location = window.location;
+
+    // Only the hash changed
+    if (location.pathname + location.search === goLive.path) {
+        return;
     }
-};
 
-goLive.server.onopen = () => {
-    goLive.once.emit("WS_CONNECTION_OPEN");
-};
+    goLive.navigate(location.pathname + location.search + location.hash);
+});
+
+goLive.connectServer();
 
 function createConnection() {
     const path = [];
@@ -260,6 +575,73 @@ function createConnection() {
     return new WebSocket(path.join(""));
 }
 
+// probeHTTP reports if the server answers the Server-Sent Events transport,
+// posting an empty event the server rejects without a session. Only the
+// transport responses carry its header, a path not mounted does not.
+function probeHTTP() {
+    return fetch("/sse", {
+        method: "POST",
+        credentials: "omit",
+        headers: { "Content-Type": "application/json" },
+        body: "{}",
+    }).then(
+        (response) =>
+            response.headers.get("{{ .Enum.SSEHeader }}") === TRANSPORT_SSE,
+        () => false
+    );
+}
+
+function createSSEConnection() {
+    const path = "/sse";
+    const source = new EventSource(path);
+
+    let sending = Promise.resolve();
+
+    const connection = {
+        readyState: WebSocket.CONNECTING,
+        onopen: null,
+        onmessage: null,
+        onclose: null,
+
+        send(data) {
+            // Chained so the server receives the events in order
+            sending = sending
+                .then(() =>
+                    fetch(path, {
+                        method: "POST",
+                        credentials: "same-origin",
+                        headers: { "Content-Type": "application/json" },
+                        body: data,
+                    })
+                )
+                .catch((e) => console.error("send error", e));
+        },
+
+        close() {
+            source.close();
+            connection.readyState = WebSocket.CLOSED;
+        },
+    };
+
+    source.onopen = () => {
+        connection.readyState = WebSocket.OPEN;
+        connection.onopen && connection.onopen();
+    };
+
+    source.onmessage = (message) => {
+        connection.onmessage && connection.onmessage(message);
+    };
+
+    source.onerror = () => {
+        // EventSource reconnects by itself, but resuming the session
+        // is handled by goLive
+        connection.close();
+        connection.onclose && connection.onclose();
+    };
+
+    return connection;
+}
+
 function createOnceEmitter() {
     const handlers = {};
     const createHandler = (name, called) => {
@@ -279,6 +661,11 @@ function createOnceEmitter() {
                 handler = createHandler(name, false);
             }
 
+            if (handler.called) {
+                cb();
+                return;
+            }
+
             handler.cbs.push(cb);
         },
         emit(name, ...attrs) {
@@ -289,6 +676,12 @@ function createOnceEmitter() {
                 return;
             }
 
+            if (handler.called) {
+                return;
+            }
+
+            handler.called = true;
+
             for (const cb of handler.cbs) {
                 cb();
             }
@@ -308,12 +701,147 @@ const findLiveClicksFromElement = (el) => {
     );
 };
 
+const findLivePollsFromElement = (el) => {
+    return el.querySelectorAll(
+        ["*[go-live-poll]:not([", GO_LIVE_CONNECTED, "])"].join("")
+    );
+};
+
 const findLiveKeyDownFromElement = (el) => {
     return el.querySelectorAll(
         ["*[go-live-keydown]:not([", GO_LIVE_CONNECTED, "])"].join("")
     );
 };
 
+const findLiveSubmitsFromElement = (el) => {
+    return el.querySelectorAll(
+        ["form[go-live-submit]:not([", GO_LIVE_CONNECTED, "])"].join("")
+    );
+};
+
+const findLiveLinksFromElement = (el) => {
+    return el.querySelectorAll(
+        ["a[go-live-link]:not([", GO_LIVE_CONNECTED, "])"].join("")
+    );
+};
+
+const findLiveUploadsFromElement = (el) => {
+    return el.querySelectorAll(
+        ["input[type=file][go-live-upload]:not([", GO_LIVE_CONNECTED, "])"].join("")
+    );
+};
+
+// Elements are scanned for bindings once, the next patches only scan the
+// elements they create.
+const findLiveEventBindingsFromElement = (el) => {
+    const elements = el.querySelectorAll(
+        ["*:not([", GO_LIVE_SCANNED, "])"].join("")
+    );
+
+    return Array.prototype.filter.call(elements, (element) => {
+        element.setAttribute(GO_LIVE_SCANNED, "");
+
+        for (const attr of element.attributes) {
+            if (attr.name.startsWith(EVENT_BINDING_PREFIX)) {
+                return true;
+            }
+        }
+        return false;
+    });
+};
+
+// domEventFromEvent picks the properties of the event the server knows,
+// with the scroll and value of the element.
+function domEventFromEvent(event, element) {
+    const domEvent = { type: event.type };
+
+    if (event.code !== undefined) {
+        domEvent.keyCode = String(event.code);
+    }
+
+    for (const name of DOM_EVENT_PROPERTIES) {
+        if (event[name] !== undefined) {
+            domEvent[name] = event[name];
+        }
+    }
+
+    if (event.type === "scroll") {
+        domEvent.scrollTop = element.scrollTop;
+        domEvent.scrollLeft = element.scrollLeft;
+    }
+
+    if (element.value !== undefined) {
+        domEvent.value = String(element.value);
+        domEvent.checked = element.checked === true;
+    }
+
+    if (event.dataTransfer) {
+        domEvent.data = event.dataTransfer.getData("text/plain");
+    }
+
+    return domEvent;
+}
+
+// rateLimitedSend returns the send function of the element, applying its
+// go-live-debounce and go-live-throttle modifiers, in milliseconds.
+function rateLimitedSend(element) {
+    const debounce = parseInt(element.getAttribute("go-live-debounce"), 10);
+    const throttle = parseInt(element.getAttribute("go-live-throttle"), 10);
+
+    // Sends the last message once there are no messages for the delay
+    if (debounce > 0) {
+        let timer = null;
+
+        return (message) => {
+            clearTimeout(timer);
+            timer = setTimeout(() => goLive.send(message), debounce);
+        };
+    }
+
+    // Sends a message at most once per interval, the last one included
+    if (throttle > 0) {
+        let last = 0;
+        let timer = null;
+        let pending = null;
+
+        return (message) => {
+            const wait = last + throttle - Date.now();
+
+            if (wait <= 0 && !timer) {
+                last = Date.now();
+                goLive.send(message);
+                return;
+            }
+
+            pending = message;
+
+            if (!timer) {
+                timer = setTimeout(() => {
+                    timer = null;
+                    last = Date.now();
+                    goLive.send(pending);
+                }, wait);
+            }
+        };
+    }
+
+    return (message) => goLive.send(message);
+}
+
+const dataFromForm = (form) => {
+    let data = {};
+    for (const [name, value] of new FormData(form)) {
+        // files are not sent within the form
+        if (typeof value !== "string") {
+            continue;
+        }
+
+        (data[name] = data[name] || []).push(value);
+    }
+
+    return data;
+};
+
 const dataFromElementAttributes = (el) => {
     const attrs = el.attributes;
     let data = {};
@@ -327,24 +855,7 @@ const dataFromElementAttributes = (el) => {
 };
 
 function getElementChild(element, index) {
-    let el = element.firstElementChild;
-
-    while (index > 0) {
-        if (!el) {
-            console.error("Element not found in path", element);
-            return;
-        }
-
-        el = el.nextSibling;
-
-        if (el.nodeType !== Node.ELEMENT_NODE) {
-            continue
-        }
-
-        index--;
-    }
-
-    return el;
+    return element.children[index || 0] || null;
 }
 
 function isElement(o) {
@@ -356,6 +867,54 @@ function isElement(o) {
         typeof o.nodeName === "string";
 }
 
+function handleNavigate(message) {
+    const url = message.m;
+    const instructions = message[EVENT_LIVE_DOM_INSTRUCTIONS_KEY];
+    const el = goLive.getLiveComponent(message[EVENT_LIVE_DOM_COMPONENT_ID_KEY]);
+
+    // Routes not live are loaded as usual
+    if (!instructions || !instructions.length || !el) {
+        window.location.assign(url);
+        return;
+    }
+
+    const wrapper = document.createElement("div");
+    wrapper.innerHTML = instructions[0][EVENT_LIVE_DOM_CONTENT_KEY];
+
+    const parent = el.parentElement;
+    parent.replaceChild(wrapper.firstElementChild, el);
+    goLive.connectElement(parent);
+
+    const next = new URL(url, window.location.href);
+    if (next.href !== window.location.href) {
+        window.history.pushState({}, "", url);
+    }
+
+    goLive.path = next.pathname + next.search;
+}
+
+function handleQuery(message) {
+    const location = window.location;
+    const params = new URLSearchParams(location.search);
+    const query = JSON.parse(message.m);
+
+    for (const name of Object.keys(query)) {
+        params.delete(name);
+
+        for (const value of query[name] || []) {
+            params.append(name, value);
+        }
+    }
+
+    const search = params.toString();
+    const url = location.pathname + (search ? "?" + search : "") + location.hash;
+
+    if (url !== location.pathname + location.search + location.hash) {
+        window.history.replaceState(window.history.state, "", url);
+        goLive.path = location.pathname + location.search;
+    }
+}
+
 function handleDiffSetAttr(message, el) {
     const { attr } = message;
 
@@ -421,8 +980,17 @@ function handleDiffMove(message, el) {
     const parent = el.parentNode
     parent.removeChild(el)
 
-    const child = getElementChild(parent, message.index)
-    parent.replaceChild(el, child)
+    parent.insertBefore(el, getElementChild(parent, message.index))
+}
+
+function handleDiffInsert(message, el) {
+    const { content } = message;
+
+    const wrapper = document.createElement("div");
+    wrapper.innerHTML = content;
+
+    el.insertBefore(wrapper.firstChild, getElementChild(el, message.index));
+    goLive.connectElement(el);
 }
 const getComponentIdFromElement = (element) => {
     const attr = element.getAttribute("go-live-component-id");
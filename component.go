@@ -1,14 +1,18 @@
 package golive
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/net/html/atom"
 
@@ -21,6 +25,8 @@ var (
 	ErrComponentNotPrepared = errors.New("Component need to be prepared")
 	ErrComponentWithoutLog  = errors.New("Component without log defined")
 	ErrComponentNil         = errors.New("Component nil")
+
+	ErrUnsupportedMethodSignature = errors.New("unsupported method signature")
 )
 
 //
@@ -54,10 +60,30 @@ type LiveComponent struct {
 
 	log       Log
 	life      *ComponentLifeCycle
+	lifeMu    sync.RWMutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	navigate  func(url string)
+	schedule  func(fn func())
+	pubsub    PubSubBackend
 	component ComponentLifeTime
 	renderer  LiveRenderer
 
-	children []*LiveComponent
+	parent     *LiveComponent
+	children   []*LiveComponent
+	exposure   componentExposure
+	validation componentValidation
+	uploads    componentUploads
+	query      componentQuery
+	props      componentProps
+	slots      componentSlots
+
+	// renders counts the renders of the whole component, by itself or by
+	// its parent
+	renders int
+
+	subscriptions componentSubscriptions
+	handlers      componentHandlers
 
 	Context ComponentContext
 }
@@ -79,7 +105,9 @@ func NewLiveComponent(name string, component ComponentLifeTime) *LiveComponent {
 func (l *LiveComponent) Create(life *ComponentLifeCycle) error {
 	var err error
 
+	l.lifeMu.Lock()
 	l.life = life
+	l.lifeMu.Unlock()
 
 	if l.log == nil {
 		return ErrComponentWithoutLog
@@ -91,6 +119,12 @@ func (l *LiveComponent) Create(life *ComponentLifeCycle) error {
 
 	l.Name = l.createUniqueName()
 
+	l.initContext()
+
+	if err := checkValidationRules(reflect.TypeOf(l.component)); err != nil {
+		return fmt.Errorf("check validation rules: %w", err)
+	}
+
 	// Get the template defined on Component
 	ts := l.component.TemplateHandler(l)
 
@@ -112,6 +146,7 @@ func (l *LiveComponent) Create(life *ComponentLifeCycle) error {
 	l.renderer.useFormatter(func(t string) string {
 		d, _ := nodeFromString(t)
 		_ = l.treatRender(d)
+		l.updateExposure(d)
 		t, _ = renderInnerHTML(d)
 		return t
 	})
@@ -134,18 +169,119 @@ func (l *LiveComponent) Create(life *ComponentLifeCycle) error {
 }
 
 func (l *LiveComponent) createChildren() error {
-	var err error
 	for _, child := range l.getChildrenComponents() {
-		child.log = l.log
-		child.Context = l.Context
-		err = child.Create(l.life)
-		if err != nil {
-			panic(err)
+		if err := l.createChild(child); err != nil {
+			return fmt.Errorf("create child %s: %w", child.Name, err)
 		}
 
 		l.children = append(l.children, child)
 	}
-	return err
+	return nil
+}
+
+func (l *LiveComponent) createChild(child *LiveComponent) error {
+	child.parent = l
+	child.log = l.log
+	child.navigate = l.navigate
+	child.schedule = l.schedule
+	child.pubsub = l.pubsub
+	child.ctx = l.ctx
+	child.Context = l.Context
+
+	l.query.mu.Lock()
+	query := l.query.initial
+	l.query.mu.Unlock()
+
+	child.bindQuery(query)
+
+	return child.Create(l.lifeCycle())
+}
+
+// updateChildren creates and mounts the children that appeared in the
+// component since the last render, and kills the ones that are gone.
+func (l *LiveComponent) updateChildren() error {
+	current := l.getChildrenComponents()
+
+	previous := make(map[*LiveComponent]bool, len(l.children))
+	for _, child := range l.children {
+		previous[child] = true
+	}
+
+	for _, child := range current {
+		if previous[child] {
+			delete(previous, child)
+			continue
+		}
+
+		if child.Exited {
+			continue
+		}
+
+		if err := l.startChild(child); err != nil {
+			l.log(LogError, "start child", logEx{"name": child.Name, "error": err})
+		}
+	}
+
+	for _, child := range l.children {
+		if !previous[child] || child.Exited {
+			continue
+		}
+
+		if err := child.Kill(); err != nil {
+			l.log(LogError, "kill child", logEx{"name": child.Name, "error": err})
+		}
+	}
+
+	l.children = current
+
+	return nil
+}
+
+// startChild creates and mounts a child that appeared in the component.
+// A child failing, even by a panic, is killed and not rendered, the
+// session keeps the rest of the page.
+func (l *LiveComponent) startChild(child *LiveComponent) (err error) {
+	defer func() {
+		if payload := recover(); payload != nil {
+			err = fmt.Errorf("start child: panic: %v", payload)
+		}
+
+		if err != nil {
+			l.discardChild(child)
+		}
+	}()
+
+	if !child.IsCreated {
+		if err := l.createChild(child); err != nil {
+			return fmt.Errorf("create child: %w", err)
+		}
+	}
+
+	if l.IsMounted && !child.IsMounted {
+		if err := child.Mount(); err != nil {
+			return fmt.Errorf("mount child: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// discardChild kills the child that failed to start, it is not started
+// again.
+func (l *LiveComponent) discardChild(child *LiveComponent) {
+	defer func() {
+		if payload := recover(); payload != nil {
+			l.log(LogError, fmt.Sprintf("discard child: panic recovered: %v", payload), logEx{"name": child.Name})
+		}
+
+		child.Exited = true
+	}()
+
+	if child.IsCreated && !child.Exited {
+		if err := child.Kill(); err != nil {
+			l.log(LogError, "discard child", logEx{"name": child.Name, "error": err})
+		}
+	}
 }
 
 func (l *LiveComponent) findComponentByID(id string) *LiveComponent {
@@ -170,6 +306,18 @@ func (l *LiveComponent) findComponentByID(id string) *LiveComponent {
 	return nil
 }
 
+// findParentByID finds the parent of id, as the owner of the slots filled
+// in the component.
+func (l *LiveComponent) findParentByID(id string) *LiveComponent {
+	for parent := l.parent; parent != nil; parent = parent.parent {
+		if parent.Name == id {
+			return parent
+		}
+	}
+
+	return nil
+}
+
 // Mount 2. the Component loading html
 func (l *LiveComponent) Mount() error {
 
@@ -198,7 +346,11 @@ func (l *LiveComponent) Mount() error {
 
 func (l *LiveComponent) MountChildren() error {
 	l.notifyStage(WillMountChildren)
-	for _, child := range l.getChildrenComponents() {
+	for _, child := range l.children {
+		if child.IsMounted {
+			continue
+		}
+
 		err := child.Mount()
 
 		if err != nil {
@@ -217,11 +369,19 @@ func (l *LiveComponent) Render() (string, error) {
 		return "", ErrComponentNil
 	}
 
+	if err := l.updateChildren(); err != nil {
+		return "", fmt.Errorf("update children: %w", err)
+	}
+
+	l.renders++
+
 	text, _, err := l.renderer.Render(l.component)
 	return text, err
 }
 
-func (l *LiveComponent) RenderChild(fn reflect.Value, _ ...reflect.Value) template.HTML {
+// RenderChild is the render function of templates. The arguments after
+// the child are its props, see exposeTagProp, and slots, see slotContent.
+func (l *LiveComponent) RenderChild(fn reflect.Value, args ...reflect.Value) template.HTML {
 
 	child, ok := fn.Interface().(*LiveComponent)
 
@@ -230,11 +390,37 @@ func (l *LiveComponent) RenderChild(fn reflect.Value, _ ...reflect.Value) templa
 		return ""
 	}
 
+	// Children killed, or failing to start, are not rendered
+	if child.Exited {
+		return ""
+	}
+
+	if len(args) > 0 {
+		props, slots := splitSlots(args)
+
+		changed, err := child.setProps(props)
+		if err != nil {
+			l.log(LogError, "render child: set props", logEx{"error": err})
+		}
+
+		if child.setSlots(slots) {
+			changed = true
+		}
+
+		// Only the props and slots can change the child while rendering the parent,
+		// changes of its own state are rendered by its own updates
+		if !changed && err == nil && child.props.rendered {
+			return template.HTML(child.renderer.state.text)
+		}
+	}
+
 	render, err := child.Render()
 	if err != nil {
 		l.log(LogError, "render child: render", logEx{"error": err})
 	}
 
+	child.props.rendered = err == nil
+
 	return template.HTML(render)
 }
 
@@ -242,9 +428,40 @@ func (l *LiveComponent) RenderChild(fn reflect.Value, _ ...reflect.Value) templa
 // differences from the last render
 // and sets the "new old" version  of render
 func (l *LiveComponent) LiveRender() (*diff, error) {
+	if l.component == nil {
+		return nil, ErrComponentNil
+	}
+
+	if err := l.updateChildren(); err != nil {
+		return nil, fmt.Errorf("update children: %w", err)
+	}
+
 	return l.renderer.LiveRender(l.component)
 }
 
+// Navigate swaps the page to the component of the route of url, as a
+// go-live-link does. It does nothing for components out of a page.
+func (l *LiveComponent) Navigate(url string) {
+	if l.navigate == nil {
+		l.log(LogWarn, "navigate: component without page", logEx{"name": l.Name, "url": url})
+		return
+	}
+
+	l.navigate(url)
+}
+
+// dispatch runs fn in the goroutine of the page events, where the session
+// renders the component, so fn can change its state. Components out of a
+// page run it in place.
+func (l *LiveComponent) dispatch(fn func()) {
+	if l.schedule == nil {
+		fn()
+		return
+	}
+
+	l.schedule(fn)
+}
+
 func (l *LiveComponent) Update() {
 	l.notifyStage(Updated)
 }
@@ -256,8 +473,14 @@ func (l *LiveComponent) UpdateWithSource(source *EventSource) {
 // Kill ...
 func (l *LiveComponent) Kill() error {
 
+	l.cancelContext()
+
 	l.KillChildren()
 
+	l.abortUploads()
+
+	l.unsubscribeAll()
+
 	l.log(LogTrace, "WillUnmount", logEx{"name": l.Name})
 
 	l.component.BeforeUnmount(l)
@@ -269,15 +492,21 @@ func (l *LiveComponent) Kill() error {
 
 	l.notifyStage(Unmounted)
 
+	l.lifeMu.Lock()
 	l.life = nil
+	l.lifeMu.Unlock()
 
 	return nil
 }
 
 func (l *LiveComponent) KillChildren() {
 	for _, child := range l.children {
+		if child.Exited {
+			continue
+		}
+
 		if err := child.Kill(); err != nil {
-			l.log(LogError, "kill child", logEx{"name": child.Name})
+			l.log(LogError, "kill child", logEx{"name": child.Name, "error": err})
 		}
 	}
 }
@@ -319,85 +548,469 @@ func jsonEscape(i string) string {
 	return s[1 : len(s)-1]
 }
 
-// SetValueInPath ...
-func (l *LiveComponent) SetValueInPath(value string, path string) error {
+// decodeValue decodes a value sent by the browser into type t. Strings
+// are taken as they are, anything else is decoded as JSON.
+func decodeValue(value string, t reflect.Type) (reflect.Value, error) {
+	n := reflect.New(t)
 
-	v := l.GetFieldFromPath(path)
-	n := reflect.New(v.Type())
-
-	if v.Kind() == reflect.String {
+	if t.Kind() == reflect.String {
 		value = `"` + jsonEscape(value) + `"`
 	}
 
 	err := json.Unmarshal([]byte(value), n.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return n.Elem(), nil
+}
+
+// SetValueInPath ...
+func (l *LiveComponent) SetValueInPath(value string, path string) error {
+
+	v := l.GetFieldFromPath(path)
+
+	n, err := decodeValue(value, v.Type())
 	if err != nil {
 		return err
 	}
 
-	v.Set(n.Elem())
+	v.Set(n)
+
+	l.ValidateField(path)
+
 	return nil
 }
 
-// InvokeMethodInPath ...
+var (
+	methodDataType     = reflect.TypeOf(map[string]string{})
+	methodDOMEventType = reflect.TypeOf(&DOMEvent{})
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+	uploadedFileType   = reflect.TypeOf(&UploadedFile{})
+	readerType         = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// InvokeMethodInPath calls the method of the component. Parameters of type
+// map[string]string receive the go-live-data-* attributes and *DOMEvent
+// the event, any other parameter is decoded from the attributes:
+// go-live-data-0, go-live-data-1... by position, the single attribute
+// when there is a single parameter, or all of them by field name when the
+// parameter is a struct. If the method returns an error, it is returned.
 func (l *LiveComponent) InvokeMethodInPath(path string, data map[string]string, domEvent *DOMEvent) error {
+	return l.invokeMethod(path, methodInput{data: data, domEvent: domEvent})
+}
+
+// InvokeMethodWithForm calls the method of the component with a submitted
+// form. Struct parameters are decoded from the form fields by name, slices
+// receive all the values of a field, bool fields are true when the field is
+// present, and map[string][]string or url.Values parameters receive the
+// form itself. Other parameters follow the rules of InvokeMethodInPath.
+func (l *LiveComponent) InvokeMethodWithForm(path string, form map[string][]string, data map[string]string) error {
+	if form == nil {
+		form = map[string][]string{}
+	}
+
+	return l.invokeMethod(path, methodInput{data: data, form: form})
+}
+
+// methodInput is everything the browser sent that can be decoded into
+// the arguments of a method
+type methodInput struct {
+	data     map[string]string
+	form     map[string][]string
+	domEvent *DOMEvent
+	file     *UploadedFile
+	reader   io.Reader
+}
+
+// isMethodInputType reports if the parameter receives an input as it is,
+// instead of a value decoded from the data
+func isMethodInputType(t reflect.Type) bool {
+	switch t {
+	case methodDataType, methodDOMEventType, uploadedFileType, readerType:
+		return true
+	}
+	return isFormType(t)
+}
+
+func (l *LiveComponent) invokeMethod(path string, in methodInput) error {
 	m := reflect.ValueOf(l.component).MethodByName(path)
 	if !m.IsValid() {
 		return fmt.Errorf("not a valid function: %v", path)
 	}
 
-	// TODO: check for errors when calling
-	switch m.Type().NumIn() {
-	case 0:
-		m.Call(nil)
-	case 1:
-		m.Call(
-			[]reflect.Value{reflect.ValueOf(data)},
-		)
-	case 2:
-		m.Call(
-			[]reflect.Value{
-				reflect.ValueOf(data),
-				reflect.ValueOf(domEvent),
-			},
-		)
+	args, err := methodArguments(m.Type(), in)
+	if err != nil {
+		return fmt.Errorf("method %s: %w", path, err)
+	}
+
+	results := m.Call(args)
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	last := results[len(results)-1]
+	if last.Type() == errorType && !last.IsNil() {
+		return &methodError{err: last.Interface().(error)}
 	}
 
 	return nil
 }
 
+// methodError is an error returned by a method of a component, it tells
+// apart the errors of the application from the errors of the event.
+type methodError struct {
+	err error
+}
+
+func (e *methodError) Error() string {
+	return e.err.Error()
+}
+
+func (e *methodError) Unwrap() error {
+	return e.err
+}
+
+func methodArguments(mt reflect.Type, in methodInput) ([]reflect.Value, error) {
+	if mt.IsVariadic() {
+		return nil, fmt.Errorf("%w: variadic parameters", ErrUnsupportedMethodSignature)
+	}
+
+	values := 0
+	for i := 0; i < mt.NumIn(); i++ {
+		if !isMethodInputType(mt.In(i)) {
+			values++
+		}
+	}
+
+	args := make([]reflect.Value, mt.NumIn())
+	position := 0
+
+	for i := 0; i < mt.NumIn(); i++ {
+		switch t := mt.In(i); {
+		case t == methodDataType:
+			args[i] = reflect.ValueOf(in.data)
+		case t == methodDOMEventType:
+			args[i] = reflect.ValueOf(in.domEvent)
+		case t == uploadedFileType:
+			args[i] = reflect.ValueOf(in.file)
+		case t == readerType:
+			args[i] = reflect.Zero(readerType)
+			if in.reader != nil {
+				args[i] = reflect.ValueOf(in.reader)
+			}
+		case isFormType(t):
+			args[i] = reflect.ValueOf(in.form).Convert(t)
+		default:
+			arg, err := methodArgument(t, position, values, in)
+			if err != nil {
+				return nil, err
+			}
+
+			args[i] = arg
+			position++
+		}
+	}
+
+	return args, nil
+}
+
+func methodArgument(t reflect.Type, position, values int, in methodInput) (reflect.Value, error) {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+		return reflect.Value{}, fmt.Errorf("%w: parameter of type %s", ErrUnsupportedMethodSignature, t)
+	}
+
+	if in.form != nil && isStructType(t) {
+		return decodeForm(in.form, t)
+	}
+
+	data := in.data
+	raw, found := data[strconv.Itoa(position)]
+
+	if !found && values == 1 && len(data) == 1 && !isStructType(t) {
+		for _, raw = range data {
+			found = true
+		}
+	}
+
+	if found {
+		v, err := decodeValue(raw, t)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("decode argument %d: %w", position, err)
+		}
+		return v, nil
+	}
+
+	if isStructType(t) {
+		return decodeStruct(data, t)
+	}
+
+	return reflect.Value{}, fmt.Errorf("missing argument %d of type %s", position, t)
+}
+
+// isFormType reports if t is map[string][]string, or a type based on it
+// like url.Values
+func isFormType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String &&
+		t.Elem().Kind() == reflect.Slice && t.Elem().Elem().Kind() == reflect.String
+}
+
+func isStructType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)
+}
+
+// decodeStruct fills the fields of a struct with the data of same name,
+// or json tag. Names are compared ignoring case, dashes and underscores,
+// because browsers lowercase the attributes names.
+func decodeStruct(data map[string]string, t reflect.Type) (reflect.Value, error) {
+	st := t
+	if t.Kind() == reflect.Ptr {
+		st = t.Elem()
+	}
+
+	normalized := make(map[string]string, len(data))
+	for key, value := range data {
+		normalized[normalizeFieldName(key)] = value
+	}
+
+	v := reflect.New(st).Elem()
+
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+
+		raw, found := normalized[normalizeFieldName(name)]
+		if !found {
+			continue
+		}
+
+		fv, err := decodeValue(raw, field.Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("decode field %s: %w", field.Name, err)
+		}
+
+		v.Field(i).Set(fv)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return v.Addr(), nil
+	}
+
+	return v, nil
+}
+
+// decodeForm fills the fields of a struct with the form fields of same
+// name, as decodeStruct does.
+func decodeForm(form map[string][]string, t reflect.Type) (reflect.Value, error) {
+	st := t
+	if t.Kind() == reflect.Ptr {
+		st = t.Elem()
+	}
+
+	normalized := make(map[string][]string, len(form))
+	for key, values := range form {
+		normalized[normalizeFieldName(key)] = values
+	}
+
+	v := reflect.New(st).Elem()
+
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+
+		values, found := normalized[normalizeFieldName(name)]
+		if !found {
+			continue
+		}
+
+		fv, err := decodeFormValues(values, field.Type)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("decode field %s: %w", field.Name, err)
+		}
+
+		v.Field(i).Set(fv)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return v.Addr(), nil
+	}
+
+	return v, nil
+}
+
+func decodeFormValues(values []string, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		s := reflect.MakeSlice(t, 0, len(values))
+
+		for _, value := range values {
+			ev, err := decodeFormValues([]string{value}, t.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			s = reflect.Append(s, ev)
+		}
+
+		return s, nil
+	case len(values) == 0:
+		return reflect.Zero(t), nil
+	case t.Kind() == reflect.Bool:
+		// Checked checkboxes are sent with their value, "on" by default
+		b, err := strconv.ParseBool(values[0])
+		return reflect.ValueOf(err != nil || b).Convert(t), nil
+	case t.Kind() != reflect.String && values[0] == "":
+		return reflect.Zero(t), nil
+	}
+
+	return decodeValue(values[0], t)
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
 func (l *LiveComponent) createUniqueName() string {
 	return l.Name + "_" + NewLiveID().GenerateSmall()
 }
 
+// getChildrenComponents finds the children in the component fields,
+// including the ones inside slices, arrays, maps and nested structs.
 func (l *LiveComponent) getChildrenComponents() []*LiveComponent {
 	components := make([]*LiveComponent, 0)
-	v := reflect.ValueOf(l.component).Elem()
-	for i := 0; i < v.NumField(); i++ {
-		if !v.Field(i).CanInterface() {
-			continue
+
+	if l.component == nil {
+		return components
+	}
+
+	return l.appendChildrenComponents(components, reflect.ValueOf(l.component).Elem())
+}
+
+var liveComponentType = reflect.TypeOf(&LiveComponent{})
+
+func (l *LiveComponent) appendChildrenComponents(components []*LiveComponent, v reflect.Value) []*LiveComponent {
+	if !v.IsValid() || !typeMayHoldComponent(v.Type()) {
+		return components
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		lc, ok := v.Interface().(*LiveComponent)
+
+		// LiveComponentWrapper holds the component itself
+		if ok && lc != nil && lc != l {
+			components = append(components, lc)
 		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			components = l.appendChildrenComponents(components, v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanInterface() {
+				continue
+			}
 
-		lc, ok := v.Field(i).Interface().(*LiveComponent)
-		if !ok {
-			continue
+			components = l.appendChildrenComponents(components, v.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			components = l.appendChildrenComponents(components, v.Index(i))
 		}
+	case reflect.Map:
+		keys := v.MapKeys()
 
-		components = append(components, lc)
+		// Map order is random, but children are created in a stable order
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+
+		for _, key := range keys {
+			components = l.appendChildrenComponents(components, v.MapIndex(key))
+		}
 	}
+
 	return components
 }
 
+var typesHoldingComponent sync.Map
+
+// typeMayHoldComponent reports if a value of the type can hold a
+// *LiveComponent, so big collections of plain data are not walked.
+func typeMayHoldComponent(t reflect.Type) bool {
+	if cached, ok := typesHoldingComponent.Load(t); ok {
+		return cached.(bool)
+	}
+
+	may := typeMayHoldComponentVisiting(t, map[reflect.Type]bool{})
+	typesHoldingComponent.Store(t, may)
+	return may
+}
+
+func typeMayHoldComponentVisiting(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == liveComponentType {
+		return true
+	}
+
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if typeMayHoldComponentVisiting(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return typeMayHoldComponentVisiting(t.Elem(), visiting)
+	}
+
+	return false
+}
+
+// lifeCycle returns the channel of the lifecycle notifications, nil once
+// the component is killed.
+func (l *LiveComponent) lifeCycle() *ComponentLifeCycle {
+	l.lifeMu.RLock()
+	defer l.lifeMu.RUnlock()
+
+	return l.life
+}
+
 func (l *LiveComponent) notifyStage(ltu LifeTimeStage) {
 	l.notifyStageWithSource(ltu, nil)
 }
 
 func (l *LiveComponent) notifyStageWithSource(ltu LifeTimeStage, source *EventSource) {
-	if l.life == nil {
+	life := l.lifeCycle()
+
+	if life == nil {
 		l.log(LogWarn, "Component life updates channel is nil", nil)
 		return
 	}
 
-	*l.life <- ComponentLifeTimeMessage{
+	*life <- ComponentLifeTimeMessage{
 		Stage:     ltu,
 		Component: l,
 		Source:    source,
@@ -417,7 +1030,12 @@ func (l *LiveComponent) addGoLiveComponentIDAttribute(template string) string {
 
 func (l *LiveComponent) generateTemplate(ts string) (*template.Template, error) {
 	return template.New(l.Name).Funcs(template.FuncMap{
-		"render": l.RenderChild,
+		"render":      l.RenderChild,
+		"fieldError":  l.FieldError,
+		"fieldErrors": l.FieldErrors,
+		"uploads":     l.Uploads,
+		"fill":        l.FillSlot,
+		"slot":        l.Slot,
 	}).Parse(ts)
 }
 
@@ -441,12 +1059,21 @@ func (l *LiveComponent) treatRender(dom *html.Node) error {
 
 			foundComponent := l.findComponentByID(cid)
 
+			if foundComponent == nil {
+				foundComponent = l.findParentByID(cid)
+			}
+
 			if foundComponent == nil {
 				return fmt.Errorf("Component not found")
 			}
 
 			f := foundComponent.GetFieldFromPath(valueAttr.Val)
 
+			if foundComponent.FieldError(valueAttr.Val) != "" {
+				removeNodeAttribute(node, "aria-invalid")
+				addNodeAttribute(node, "aria-invalid", "true")
+			}
+
 			if inputTypeAttr := getAttribute(node, "type"); inputTypeAttr != nil {
 				switch inputTypeAttr.Val {
 				case "checkbox":
@@ -0,0 +1,45 @@
+package golive
+
+import "context"
+
+// Ctx returns the context of the component, cancelled when the component
+// is killed. Kills happen when the browser leaves the page, the session
+// expires or the server shuts down, so background work started by the
+// hooks should stop when it is done:
+//
+//	func (c *Clock) Mounted(l *golive.LiveComponent) {
+//		go func() {
+//			for {
+//				select {
+//				case <-l.Ctx().Done():
+//					return
+//				case <-time.After(time.Second):
+//					c.Commit()
+//				}
+//			}
+//		}()
+//	}
+func (l *LiveComponent) Ctx() context.Context {
+	if l.ctx == nil {
+		return context.Background()
+	}
+
+	return l.ctx
+}
+
+// initContext derives the context of the component from the context of
+// its parent, or of its session.
+func (l *LiveComponent) initContext() {
+	parent := l.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	l.ctx, l.cancel = context.WithCancel(parent)
+}
+
+func (l *LiveComponent) cancelContext() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+}
@@ -0,0 +1,75 @@
+package golive
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type contextTicker struct {
+	LiveComponentWrapper
+	stopped chan struct{}
+}
+
+func (c *contextTicker) Mounted(l *LiveComponent) {
+	go func() {
+		<-l.Ctx().Done()
+		close(c.stopped)
+	}()
+}
+
+func (c *contextTicker) TemplateHandler(_ *LiveComponent) string {
+	return `<p>tick</p>`
+}
+
+func TestLiveComponent_CtxCancelledOnKill(t *testing.T) {
+	item := NewLiveComponent("Item", &batchItem{})
+	list := NewLiveComponent("List", &batchList{Item: item})
+	list.log = NewLoggerBasic().Log
+
+	if list.Ctx() == nil {
+		t.Fatal("nil context before create")
+	}
+
+	if err := list.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Ctx().Err() != nil || list.Ctx().Err() != nil {
+		t.Fatal("context cancelled before kill")
+	}
+
+	if err := list.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	if list.Ctx().Err() == nil || item.Ctx().Err() == nil {
+		t.Error("context not cancelled on kill")
+	}
+}
+
+func TestLiveComponent_CtxCancelledOnShutdown(t *testing.T) {
+	server := NewServer()
+	server.Log = func(int, string, map[string]interface{}) {}
+
+	ticker := &contextTicker{stopped: make(chan struct{})}
+	lc := NewLiveComponent("Ticker", ticker)
+	lc.log = server.Log
+
+	if _, err := server.HandleFirstRequest(lc, PageContent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ticker.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("background work not stopped")
+	}
+}
@@ -0,0 +1,165 @@
+package golive
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ComponentEvent is an event emitted by a component to its parents.
+type ComponentEvent struct {
+	Name    string
+	Payload interface{}
+
+	// Source is the component that emitted the event
+	Source *LiveComponent
+
+	stopped bool
+}
+
+// StopPropagation keeps the event from reaching the next parents.
+func (e *ComponentEvent) StopPropagation() {
+	e.stopped = true
+}
+
+type componentHandlers struct {
+	mu       sync.RWMutex
+	handlers map[string][]func(e *ComponentEvent)
+}
+
+// On registers a handler of the events of name emitted by the children of
+// the component, at any depth. Handlers are usually registered in the
+// Create hook.
+func (l *LiveComponent) On(name string, handler func(e *ComponentEvent)) {
+	l.handlers.mu.Lock()
+	defer l.handlers.mu.Unlock()
+
+	if l.handlers.handlers == nil {
+		l.handlers.handlers = make(map[string][]func(e *ComponentEvent))
+	}
+
+	l.handlers.handlers[name] = append(l.handlers.handlers[name], handler)
+}
+
+// Emit sends the event to the parents of the component, from the closest
+// one, until a handler stops its propagation. Only the parents whose state
+// was changed by their handlers are updated.
+func (l *LiveComponent) Emit(name string, payload interface{}) {
+	e := &ComponentEvent{
+		Name:    name,
+		Payload: payload,
+		Source:  l,
+	}
+
+	for parent := l.parent; parent != nil && !e.stopped; parent = parent.parent {
+		parent.handlers.mu.RLock()
+		handlers := parent.handlers.handlers[name]
+		parent.handlers.mu.RUnlock()
+
+		if len(handlers) == 0 || parent.Exited {
+			continue
+		}
+
+		before := componentState(parent.component)
+
+		for _, handler := range handlers {
+			handler(e)
+		}
+
+		if componentState(parent.component) != before {
+			parent.Update()
+		}
+	}
+}
+
+// componentState writes the state of the component, following its
+// pointers, slices and maps, so changes made through them are found.
+// Children are only identified, they render by themselves.
+func componentState(c interface{}) string {
+	var b strings.Builder
+	writeState(&b, reflect.ValueOf(c), map[uintptr]bool{})
+	return b.String()
+}
+
+func writeState(b *strings.Builder, v reflect.Value, visited map[uintptr]bool) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+
+	if v.Type() == liveComponentType {
+		fmt.Fprintf(b, "child:%x", v.Pointer())
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+
+		if visited[v.Pointer()] {
+			fmt.Fprintf(b, "@%x", v.Pointer())
+			return
+		}
+
+		visited[v.Pointer()] = true
+
+		b.WriteString("&")
+		writeState(b, v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+
+		b.WriteString(v.Elem().Type().String())
+		writeState(b, v.Elem(), visited)
+	case reflect.Struct:
+		b.WriteString("{")
+		for i := 0; i < v.NumField(); i++ {
+			writeState(b, v.Field(i), visited)
+			b.WriteString(",")
+		}
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			writeState(b, v.Index(i), visited)
+			b.WriteString(",")
+		}
+		b.WriteString("]")
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var entry strings.Builder
+			writeState(&entry, iter.Key(), visited)
+			entry.WriteString(":")
+			writeState(&entry, iter.Value(), visited)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+
+		b.WriteString("map[")
+		b.WriteString(strings.Join(entries, ","))
+		b.WriteString("]")
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		fmt.Fprintf(b, "%x", v.Pointer())
+	default:
+		fmt.Fprintf(b, "%#v", v)
+	}
+}
@@ -0,0 +1,146 @@
+package golive
+
+import "testing"
+
+type eventSlider struct {
+	LiveComponentWrapper
+	Size int
+}
+
+func (s *eventSlider) Resize(size int) {
+	s.Size = size
+	s.Component.Emit("change", size)
+}
+
+func (s *eventSlider) TemplateHandler(_ *LiveComponent) string {
+	return `<input type="range" value="{{ .Size }}"/>`
+}
+
+type eventForm struct {
+	LiveComponentWrapper
+	Size    int
+	Stop    bool
+	Renders int
+	Slider  *LiveComponent
+}
+
+func (f *eventForm) Render() int {
+	f.Renders++
+	return f.Renders
+}
+
+func (f *eventForm) Create(l *LiveComponent) {
+	f.LiveComponentWrapper.Create(l)
+
+	l.On("change", func(e *ComponentEvent) {
+		f.Size = e.Payload.(int)
+
+		if f.Stop {
+			e.StopPropagation()
+		}
+	})
+}
+
+func (f *eventForm) TemplateHandler(_ *LiveComponent) string {
+	return `<form><b>{{ .Render }}</b>{{ render .Slider }}</form>`
+}
+
+type eventPage struct {
+	LiveComponentWrapper
+	Changes []*LiveComponent
+	Ignore  bool
+	Renders int
+	Form    *LiveComponent
+}
+
+func (p *eventPage) Render() int {
+	p.Renders++
+	return p.Renders
+}
+
+func (p *eventPage) Create(l *LiveComponent) {
+	p.LiveComponentWrapper.Create(l)
+
+	l.On("change", func(e *ComponentEvent) {
+		if !p.Ignore {
+			p.Changes = append(p.Changes, e.Source)
+		}
+	})
+}
+
+func (p *eventPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div><b>{{ .Render }}</b>{{ render .Form }}</div>`
+}
+
+func TestComponent_EmitBubbles(t *testing.T) {
+	slider := &eventSlider{}
+	form := &eventForm{Slider: NewLiveComponent("Slider", slider)}
+	page := &eventPage{Form: NewLiveComponent("Form", form)}
+
+	life := make(ComponentLifeCycle, 16)
+
+	c := NewLiveComponent("Page", page)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(&life); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSession()
+	s.log = c.log
+
+	// resize renders the components updated by the event, as the session
+	// does, returning how many times the page and the form rendered
+	sliderComponent := form.Slider
+	resize := func(size string) (int, int) {
+		if err := sliderComponent.InvokeMethodInPath("Resize", map[string]string{"0": size}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		batch := newUpdateBatch()
+		for len(life) > 0 {
+			if msg := <-life; msg.Stage == Updated {
+				batch.add(msg.Component, msg.Source)
+			}
+		}
+
+		pageRenders, formRenders := page.Renders, form.Renders
+		s.renderBatch(batch)
+
+		return page.Renders - pageRenders, form.Renders - formRenders
+	}
+
+	pageRenders, formRenders := resize("12")
+
+	if form.Size != 12 || len(page.Changes) != 1 || page.Changes[0] != sliderComponent {
+		t.Fatal("event not handled by the parents", form.Size, page.Changes)
+	}
+
+	if pageRenders != 1 || formRenders != 1 {
+		t.Error("parents changed by the event not rendered once, renders", pageRenders, formRenders)
+	}
+
+	// The handlers run, but the state of the parents stays the same
+	page.Ignore = true
+
+	if pageRenders, formRenders := resize("12"); pageRenders != 0 || formRenders != 0 {
+		t.Error("parents not changed by the event rendered, renders", pageRenders, formRenders)
+	}
+
+	page.Ignore = false
+	form.Stop = true
+
+	pageRenders, formRenders = resize("20")
+
+	if form.Size != 20 || len(page.Changes) != 1 {
+		t.Error("event propagated after stopped", form.Size, page.Changes)
+	}
+
+	if pageRenders != 0 || formRenders != 1 {
+		t.Error("only the parent stopping the event should render, renders", pageRenders, formRenders)
+	}
+}
@@ -0,0 +1,250 @@
+package golive
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+var ErrNotExposed = errors.New("not exposed to the browser")
+
+// ExposedMethodsDeclarer is implemented by components declaring which
+// methods the browser can invoke. Without it, only the methods referenced
+// by the last render of the template can be invoked.
+type ExposedMethodsDeclarer interface {
+	ExposedMethods() []string
+}
+
+// ExposeTagKey is the struct tag declaring a field bindable by the
+// browser, as in `golive:"bind"`. When a component has any field with the
+// tag, nested structs included, only tagged fields, and fields inside them,
+// can be set. Without it, only the paths referenced by the last render of
+// the template can be set.
+const ExposeTagKey = "golive"
+
+const exposeTagBind = "bind"
+
+// Attributes referencing methods and paths in templates
+var (
+	exposedMethodAttributes = []string{"go-live-click", "go-live-keydown", "go-live-submit", "go-live-upload", "go-live-poll"}
+	exposedPathAttributes   = []string{"go-live-input"}
+)
+
+// EventBindingPrefix prefixes the attributes binding a method to any
+// browser event, as go-live-on:change="Save".
+const EventBindingPrefix = "go-live-on:"
+
+type componentExposure struct {
+	mu      sync.RWMutex
+	methods map[string]bool
+	paths   map[string]bool
+	uploads map[string]UploadLimits
+}
+
+// updateExposure collects the methods and paths referenced by the nodes
+// of the component in the rendered dom.
+func (l *LiveComponent) updateExposure(dom *html.Node) {
+	methods := make(map[string]bool)
+	paths := make(map[string]bool)
+	uploads := make(map[string]UploadLimits)
+
+	for _, node := range getAllChildrenRecursive(dom) {
+		if node.Type != html.ElementNode {
+			continue
+		}
+
+		for _, key := range exposedMethodAttributes {
+			if attr := getAttribute(node, key); attr != nil && l.ownsNode(node) {
+				methods[attr.Val] = true
+			}
+		}
+
+		for _, attr := range node.Attr {
+			if strings.HasPrefix(attr.Key, EventBindingPrefix) && l.ownsNode(node) {
+				methods[attr.Val] = true
+			}
+		}
+
+		for _, key := range exposedPathAttributes {
+			if attr := getAttribute(node, key); attr != nil && l.ownsNode(node) {
+				paths[attr.Val] = true
+			}
+		}
+
+		if attr := getAttribute(node, "go-live-upload"); attr != nil && l.ownsNode(node) {
+			uploads[attr.Val] = uploadLimitsFromNode(node)
+		}
+	}
+
+	l.exposure.mu.Lock()
+	defer l.exposure.mu.Unlock()
+
+	l.exposure.methods = methods
+	l.exposure.paths = paths
+	l.exposure.uploads = uploads
+}
+
+// uploadLimitsFromNode reads the limits of a go-live-upload input from
+// its accept and go-live-upload-max-size attributes.
+func uploadLimitsFromNode(node *html.Node) UploadLimits {
+	limits := UploadLimits{MaxSize: DefaultUploadMaxSize}
+
+	if attr := getAttribute(node, "accept"); attr != nil && attr.Val != "" {
+		limits.Accept = strings.Split(attr.Val, ",")
+	}
+
+	if attr := getAttribute(node, "go-live-upload-max-size"); attr != nil {
+		if size, err := strconv.ParseInt(attr.Val, 10, 64); err == nil {
+			limits.MaxSize = size
+		}
+	}
+
+	return limits
+}
+
+// uploadLimits returns the limits of the input of the last render bound
+// to the method, or the defaults when there is none.
+func (l *LiveComponent) uploadLimits(method string) UploadLimits {
+	l.exposure.mu.RLock()
+	defer l.exposure.mu.RUnlock()
+
+	if limits, ok := l.exposure.uploads[method]; ok {
+		return limits
+	}
+
+	return UploadLimits{MaxSize: DefaultUploadMaxSize}
+}
+
+// ownsNode reports if the node belongs to the component and not to a child.
+func (l *LiveComponent) ownsNode(node *html.Node) bool {
+	cid, err := componentIDFromNode(node)
+	return err == nil && cid == l.Name
+}
+
+// IsMethodExposed reports if the browser is allowed to invoke the method.
+func (l *LiveComponent) IsMethodExposed(name string) bool {
+	if declarer, ok := l.component.(ExposedMethodsDeclarer); ok {
+		for _, method := range declarer.ExposedMethods() {
+			if method == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	l.exposure.mu.RLock()
+	defer l.exposure.mu.RUnlock()
+
+	return l.exposure.methods[name]
+}
+
+// IsPathExposed reports if the browser is allowed to set the path.
+func (l *LiveComponent) IsPathExposed(path string) bool {
+	t := reflect.TypeOf(l.component)
+
+	if hasBindTag(t) {
+		return pathHasBindTag(t, path)
+	}
+
+	l.exposure.mu.RLock()
+	defer l.exposure.mu.RUnlock()
+
+	return l.exposure.paths[path]
+}
+
+// bindTagTypes caches hasBindTag by type
+var bindTagTypes sync.Map
+
+// hasBindTag reports if any field reachable from the type, nested structs
+// included, is tagged.
+func hasBindTag(t reflect.Type) bool {
+	if has, ok := bindTagTypes.Load(t); ok {
+		return has.(bool)
+	}
+
+	has := typeHasBindTag(t, map[reflect.Type]bool{})
+	bindTagTypes.Store(t, has)
+
+	return has
+}
+
+func typeHasBindTag(t reflect.Type, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if isBindField(f) || typeHasBindTag(f.Type, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBindField(f reflect.StructField) bool {
+	for _, option := range strings.Split(f.Tag.Get(ExposeTagKey), ",") {
+		if option == exposeTagBind {
+			return true
+		}
+	}
+	return false
+}
+
+// pathHasBindTag walks the path through the type, it has the tag when any
+// field in the way is tagged.
+func pathHasBindTag(t reflect.Type, path string) bool {
+	for _, s := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if _, err := strconv.Atoi(s); err == nil {
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				t = t.Elem()
+				continue
+			}
+			return false
+		}
+
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+
+		f, ok := t.FieldByName(s)
+		if !ok {
+			return false
+		}
+
+		if isBindField(f) {
+			return true
+		}
+
+		t = f.Type
+	}
+
+	return false
+}
+
+func (l *LiveComponent) rejectBrowserEvent(kind, name string) error {
+	l.log(LogWarn, "browser event rejected: "+kind+" not exposed", logEx{"Component": l.Name, kind: name})
+	return fmt.Errorf("%s %s: %w", kind, name, ErrNotExposed)
+}
@@ -0,0 +1,220 @@
+package golive
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type exposureTemplate struct {
+	LiveComponentWrapper
+	Name   string
+	Secret string
+	Child  *LiveComponent
+}
+
+func (e *exposureTemplate) Save()   {}
+func (e *exposureTemplate) Delete() {}
+
+func (e *exposureTemplate) TemplateHandler(_ *LiveComponent) string {
+	return `<div>
+		<input go-live-input="Name" />
+		<button go-live-click="Save">Save</button>
+		{{ render .Child }}
+	</div>`
+}
+
+type exposureChild struct {
+	LiveComponentWrapper
+}
+
+func (e *exposureChild) TemplateHandler(_ *LiveComponent) string {
+	return `<button go-live-click="Delete">Delete</button>`
+}
+
+type exposureDeclared struct {
+	LiveComponentWrapper
+	Filter exposureFilter `golive:"bind"`
+	Tasks  []exposureTask
+	Secret string
+}
+
+type exposureFilter struct {
+	Writer string
+}
+
+type exposureTask struct {
+	Done bool `golive:"bind"`
+	Text string
+}
+
+func (e *exposureDeclared) ExposedMethods() []string {
+	return []string{"Save"}
+}
+
+func (e *exposureDeclared) Save() {}
+
+func TestComponent_ExposureFromTemplate(t *testing.T) {
+	c := NewLiveComponent("Exposure", &exposureTemplate{
+		Child: NewLiveComponent("Child", &exposureChild{}),
+	})
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsMethodExposed("Save") || !c.IsPathExposed("Name") {
+		t.Error("template references should be exposed")
+	}
+
+	if c.IsMethodExposed("Delete") || c.IsMethodExposed("Commit") || c.IsPathExposed("Secret") {
+		t.Error("methods and paths not referenced should not be exposed")
+	}
+
+	page := NewLivePage(c)
+	err := page.HandleBrowserEvent(BrowserEvent{
+		Name:        EventLiveInput,
+		ComponentID: c.Name,
+		StateKey:    "Secret",
+		StateValue:  "leaked",
+	})
+
+	if !errors.Is(err, ErrNotExposed) {
+		t.Error("expecting not exposed error, received", err)
+	}
+}
+
+func TestComponent_ExposureDeclared(t *testing.T) {
+	c := NewLiveComponent("Exposure", &exposureDeclared{})
+
+	if !c.IsMethodExposed("Save") || c.IsMethodExposed("Commit") {
+		t.Error("only declared methods should be exposed")
+	}
+
+	for path, exposed := range map[string]bool{
+		"Filter":        true,
+		"Filter.Writer": true,
+		"Tasks.1.Done":  true,
+		"Tasks.1.Text":  false,
+		"Tasks":         false,
+		"Secret":        false,
+	} {
+		if c.IsPathExposed(path) != exposed {
+			t.Error("path", path, "exposed should be", exposed)
+		}
+	}
+}
+
+type exposureBoard struct {
+	LiveComponentWrapper
+	Dropped *DOMEvent
+}
+
+func (b *exposureBoard) Drag() {}
+
+func (b *exposureBoard) Drop(e *DOMEvent) {
+	b.Dropped = e
+}
+
+func (b *exposureBoard) TemplateHandler(_ *LiveComponent) string {
+	return `<div>
+		<p draggable="true" go-live-on:dragstart="Drag">Card</p>
+		<ul go-live-on:drop="Drop"></ul>
+	</div>`
+}
+
+func TestComponent_ExposureEventBindings(t *testing.T) {
+	board := &exposureBoard{}
+	c := NewLiveComponent("Board", board)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsMethodExposed("Drag") || !c.IsMethodExposed("Drop") {
+		t.Error("bound methods should be exposed")
+	}
+
+	var event BrowserEvent
+	err := json.Unmarshal([]byte(`{
+		"name": "`+EventLiveMethod+`",
+		"component_id": "`+c.Name+`",
+		"method_name": "Drop",
+		"dom_event": {"type": "drop", "clientX": 12.5, "shiftKey": true, "data": "card-1"}
+	}`), &event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewLivePage(c).HandleBrowserEvent(event); err != nil {
+		t.Fatal(err)
+	}
+
+	e := board.Dropped
+	if e == nil || e.Type != "drop" || e.ClientX != 12.5 || !e.ShiftKey || e.Data != "card-1" {
+		t.Error("dom event not received", e)
+	}
+}
+
+type exposureNested struct {
+	LiveComponentWrapper
+	Tasks  []exposureTask
+	Secret string
+}
+
+func TestComponent_ExposureNestedBindTag(t *testing.T) {
+	c := NewLiveComponent("Exposure", &exposureNested{})
+
+	for path, exposed := range map[string]bool{
+		"Tasks.1.Done": true,
+		"Tasks.1.Text": false,
+		"Secret":       false,
+	} {
+		if c.IsPathExposed(path) != exposed {
+			t.Error("path", path, "exposed should be", exposed)
+		}
+	}
+}
+
+func TestComponent_ExposureDisconnect(t *testing.T) {
+	child := NewLiveComponent("Child", &exposureChild{})
+	c := NewLiveComponent("Exposure", &exposureTemplate{Child: child})
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	page := NewLivePage(c)
+
+	for _, id := range []string{c.Name, child.Name} {
+		err := page.HandleBrowserEvent(BrowserEvent{Name: EventLiveDisconnect, ComponentID: id})
+		if !errors.Is(err, ErrNotExposed) {
+			t.Error(id, "expecting not exposed error, received", err)
+		}
+	}
+
+	if child.Exited || c.Exited {
+		t.Fatal("component killed by the browser")
+	}
+
+	page.markConnected(child.Name)
+
+	if err := page.HandleBrowserEvent(BrowserEvent{Name: EventLiveDisconnect, ComponentID: child.Name}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !child.Exited {
+		t.Error("connected child not disconnected")
+	}
+}
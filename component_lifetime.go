@@ -36,5 +36,6 @@ type EventSource struct {
 type EventSourceType string
 
 const (
-	EventSourceInput = "input"
+	EventSourceInput    = "input"
+	EventSourceNavigate = "navigate"
 )
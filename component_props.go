@@ -0,0 +1,152 @@
+package golive
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// exposeTagProp is the option of ExposeTagKey declaring a field set by the
+// parent when rendering the component, as in `golive:"prop"`, named as the
+// field, or `golive:"prop=title"`. The parent passes props as key and
+// value arguments of render:
+//
+//	{{ render .Card "Title" .Name "Count" 3 }}
+//
+// When the props did not change, the last render of the child is used.
+// Props holding pointers, maps or slices always render the child again, as
+// the parent can change them in place.
+const exposeTagProp = "prop"
+
+var typesPropFields sync.Map
+
+// propFields returns the indexes of the prop fields of the struct type by
+// prop name.
+func propFields(t reflect.Type) map[string][]int {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if cached, ok := typesPropFields.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int)
+
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+
+			for _, option := range strings.Split(f.Tag.Get(ExposeTagKey), ",") {
+				if option == exposeTagProp {
+					fields[f.Name] = f.Index
+				} else if strings.HasPrefix(option, exposeTagProp+"=") {
+					fields[option[len(exposeTagProp)+1:]] = f.Index
+				}
+			}
+		}
+	}
+
+	typesPropFields.Store(t, fields)
+	return fields
+}
+
+type componentProps struct {
+	// rendered is set once the component was rendered by its parent
+	rendered bool
+}
+
+// setProps assigns the props passed as key and value arguments, reporting
+// if any of them changed.
+func (l *LiveComponent) setProps(args []reflect.Value) (bool, error) {
+	if len(args)%2 != 0 {
+		return false, fmt.Errorf("props of %s: odd number of arguments", l.Name)
+	}
+
+	if l.component == nil {
+		return false, ErrComponentNil
+	}
+
+	v := reflect.ValueOf(l.component).Elem()
+	fields := propFields(v.Type())
+	changed := false
+
+	for i := 0; i < len(args); i += 2 {
+		key, ok := indirectInterface(args[i]).Interface().(string)
+		if !ok {
+			return changed, fmt.Errorf("props of %s: key %d is not a string", l.Name, i/2)
+		}
+
+		index, ok := fields[key]
+		if !ok {
+			return changed, fmt.Errorf("props of %s: unknown prop %s", l.Name, key)
+		}
+
+		field := v.FieldByIndex(index)
+
+		value, err := propValue(indirectInterface(args[i+1]), field.Type())
+		if err != nil {
+			return changed, fmt.Errorf("props of %s: prop %s: %w", l.Name, key, err)
+		}
+
+		if !sharesState(value) && reflect.DeepEqual(field.Interface(), value.Interface()) {
+			continue
+		}
+
+		field.Set(value)
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// sharesState reports if the value refers to memory the parent can change
+// in place, without the props comparing different.
+func sharesState(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return !v.IsNil()
+	case reflect.Interface:
+		return !v.IsNil() && sharesState(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if sharesState(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if sharesState(v.Field(i)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func propValue(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case !v.IsValid():
+		return reflect.Zero(t), nil
+	case v.Type().AssignableTo(t):
+		return v, nil
+	case v.Type().ConvertibleTo(t) && v.Kind() != reflect.String && t.Kind() != reflect.String:
+		return v.Convert(t), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s is not assignable to %s", v.Type(), t)
+}
+
+// indirectInterface returns the value inside an interface value, as
+// template arguments arrive.
+func indirectInterface(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem()
+	}
+	return v
+}
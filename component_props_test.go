@@ -0,0 +1,149 @@
+package golive
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type propsCard struct {
+	LiveComponentWrapper
+	Title   string  `golive:"prop"`
+	Count   float64 `golive:"prop=count"`
+	Renders int
+}
+
+func (c *propsCard) Render() int {
+	c.Renders++
+	return c.Renders
+}
+
+func (c *propsCard) TemplateHandler(_ *LiveComponent) string {
+	return `<div><h1>{{ .Title }}</h1><span>{{ .Count }}</span><i>{{ .Render }}</i></div>`
+}
+
+type propsPage struct {
+	LiveComponentWrapper
+	Name  string
+	Other string
+	Card  *LiveComponent
+}
+
+func (p *propsPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div><p>{{ .Other }}</p>{{ render .Card "Title" .Name "count" 3 }}</div>`
+}
+
+func TestComponent_RenderChildProps(t *testing.T) {
+	card := &propsCard{}
+	page := &propsPage{Name: "Dune", Card: NewLiveComponent("Card", card)}
+
+	c := NewLiveComponent("Page", page)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if card.Title != "Dune" || card.Count != 3 || !strings.Contains(rendered, ">Dune</h1>") {
+		t.Fatal("props not passed", card, rendered)
+	}
+
+	page.Other = "changed"
+	if _, err := c.LiveRender(); err != nil {
+		t.Fatal(err)
+	}
+
+	if card.Renders != 1 {
+		t.Error("child rendered without props changes, renders", card.Renders)
+	}
+
+	page.Name = "Emma"
+	d, err := c.LiveRender()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if card.Title != "Emma" || card.Renders != 2 || len(d.instructions) == 0 {
+		t.Error("child not rendered after props changed", card, d.instructions)
+	}
+}
+
+type propsUser struct {
+	Name string
+}
+
+type propsUserCard struct {
+	propsCard
+	User *propsUser `golive:"prop"`
+}
+
+func (c *propsUserCard) TemplateHandler(_ *LiveComponent) string {
+	return `<div><h1>{{ .User.Name }}</h1><i>{{ .Render }}</i></div>`
+}
+
+type propsUserPage struct {
+	LiveComponentWrapper
+	User *propsUser
+	Card *LiveComponent
+}
+
+func (p *propsUserPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div>{{ render .Card "User" .User }}</div>`
+}
+
+func TestComponent_RenderChildPointerProps(t *testing.T) {
+	card := &propsUserCard{}
+	page := &propsUserPage{User: &propsUser{Name: "Dune"}, Card: NewLiveComponent("Card", card)}
+
+	c := NewLiveComponent("Page", page)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changed in place, the prop is the same pointer
+	page.User.Name = "Emma"
+
+	rendered, err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if card.Renders != 2 || !strings.Contains(rendered, ">Emma</h1>") {
+		t.Error("child not rendered after a pointer prop changed in place", card.Renders, rendered)
+	}
+}
+
+func TestComponent_SetPropsErrors(t *testing.T) {
+	c := NewLiveComponent("Card", &propsCard{})
+
+	if _, err := c.setProps(valuesOf("Title")); err == nil {
+		t.Error("expecting odd arguments error")
+	}
+
+	if _, err := c.setProps(valuesOf("Renders", 1)); err == nil {
+		t.Error("expecting unknown prop error")
+	}
+
+	if _, err := c.setProps(valuesOf("Title", 1)); err == nil {
+		t.Error("expecting type error")
+	}
+}
+
+func valuesOf(args ...interface{}) []reflect.Value {
+	values := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		values[i] = reflect.ValueOf(arg)
+	}
+	return values
+}
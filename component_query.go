@@ -0,0 +1,213 @@
+package golive
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// exposeTagQuery is the option of ExposeTagKey binding a field to a query
+// parameter of the page url, as in `golive:"query"`, named as the field,
+// or `golive:"query=page"`. Bound fields are set from the url of the
+// first request, or of a live navigation, and the browser url follows
+// their changes. Zero values are left out of the url.
+const exposeTagQuery = "query"
+
+// queryField is a field of a component bound to a query parameter
+type queryField struct {
+	param string
+	index []int
+}
+
+var typesQueryFields sync.Map
+
+// queryFields returns the bound fields of the struct type, including the
+// ones of embedded and nested structs.
+func queryFields(t reflect.Type) []queryField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if cached, ok := typesQueryFields.Load(t); ok {
+		return cached.([]queryField)
+	}
+
+	fields := appendQueryFields(nil, t, nil)
+	typesQueryFields.Store(t, fields)
+	return fields
+}
+
+func appendQueryFields(fields []queryField, t reflect.Type, index []int) []queryField {
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if param, ok := queryParamName(f); ok {
+			fields = append(fields, queryField{param: param, index: fieldIndex})
+			continue
+		}
+
+		if f.Type.Kind() == reflect.Struct {
+			fields = appendQueryFields(fields, f.Type, fieldIndex)
+		}
+	}
+
+	return fields
+}
+
+func queryParamName(f reflect.StructField) (string, bool) {
+	for _, option := range strings.Split(f.Tag.Get(ExposeTagKey), ",") {
+		if option == exposeTagQuery {
+			return f.Name, true
+		}
+
+		if strings.HasPrefix(option, exposeTagQuery+"=") {
+			return option[len(exposeTagQuery)+1:], true
+		}
+	}
+
+	return "", false
+}
+
+type componentQuery struct {
+	mu      sync.Mutex
+	initial url.Values
+	last    string
+}
+
+// bindQuery sets the bound fields from the query of the page url. The
+// values are kept for the children created later.
+func (l *LiveComponent) bindQuery(query url.Values) {
+	l.query.mu.Lock()
+	defer l.query.mu.Unlock()
+
+	l.query.initial = query
+
+	if l.component == nil {
+		return
+	}
+
+	v := reflect.ValueOf(l.component).Elem()
+
+	for _, field := range queryFields(v.Type()) {
+		values, ok := query[field.param]
+		if !ok {
+			continue
+		}
+
+		fv := v.FieldByIndex(field.index)
+
+		decoded, err := decodeFormValues(values, fv.Type())
+		if err != nil {
+			l.log(LogWarn, "bind query: decode parameter", logEx{"name": l.Name, "param": field.param, "error": err})
+			continue
+		}
+
+		fv.Set(decoded)
+	}
+
+	l.query.last = encodeQuery(l.queryValues())
+}
+
+// queryValues returns the values of the bound fields by parameter.
+func (l *LiveComponent) queryValues() map[string][]string {
+	if l.component == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(l.component).Elem()
+	fields := queryFields(v.Type())
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	query := make(map[string][]string, len(fields))
+
+	for _, field := range fields {
+		query[field.param] = queryParamValues(v.FieldByIndex(field.index))
+	}
+
+	return query
+}
+
+func queryParamValues(v reflect.Value) []string {
+	if v.IsZero() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, fmt.Sprint(v.Index(i).Interface()))
+		}
+		return values
+	}
+
+	return []string{fmt.Sprint(v.Interface())}
+}
+
+// queryUpdates returns the query parameters of the component and its
+// children that changed since the last call, nil when none did.
+func (l *LiveComponent) queryUpdates() map[string][]string {
+	var updates map[string][]string
+
+	query := l.queryValues()
+
+	if query != nil {
+		encoded := encodeQuery(query)
+
+		l.query.mu.Lock()
+		changed := encoded != l.query.last
+		l.query.last = encoded
+		l.query.mu.Unlock()
+
+		if changed {
+			updates = query
+		}
+	}
+
+	for _, child := range l.children {
+		for param, values := range child.queryUpdates() {
+			if updates == nil {
+				updates = make(map[string][]string)
+			}
+			updates[param] = values
+		}
+	}
+
+	return updates
+}
+
+// encodeQuery encodes all the parameters, including the ones without
+// values, to compare queries
+func encodeQuery(query map[string][]string) string {
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	var sb strings.Builder
+	for _, param := range params {
+		sb.WriteString(url.QueryEscape(param))
+		for _, value := range query[param] {
+			sb.WriteString("=" + url.QueryEscape(value))
+		}
+		sb.WriteString("&")
+	}
+
+	return sb.String()
+}
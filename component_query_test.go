@@ -0,0 +1,112 @@
+package golive
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type queryPaging struct {
+	Page int `golive:"query=page"`
+}
+
+type queryFilter struct {
+	LiveComponentWrapper
+	Search   string   `golive:"query=q"`
+	Genres   []string `golive:"query"`
+	Finished bool     `golive:"query=finished"`
+	Paging   queryPaging
+	Other    string
+}
+
+func (f *queryFilter) TemplateHandler(_ *LiveComponent) string {
+	return `<div>{{ .Search }} {{ .Paging.Page }}</div>`
+}
+
+func TestComponent_BindQuery(t *testing.T) {
+	filter := &queryFilter{Other: "kept"}
+	c := NewLiveComponent("Filter", filter)
+	c.log = NewLoggerBasic().Log
+
+	c.bindQuery(url.Values{
+		"q":        {"dune"},
+		"Genres":   {"scifi", "drama"},
+		"finished": {"true"},
+		"page":     {"3"},
+		"Other":    {"ignored"},
+	})
+
+	if filter.Search != "dune" || len(filter.Genres) != 2 || !filter.Finished || filter.Paging.Page != 3 || filter.Other != "kept" {
+		t.Fatal("query not bound", filter)
+	}
+
+	if updates := c.queryUpdates(); updates != nil {
+		t.Error("expecting no updates before changes, received", updates)
+	}
+
+	filter.Search = ""
+	filter.Paging.Page = 4
+
+	updates := c.queryUpdates()
+
+	if _, ok := updates["q"]; !ok || len(updates["q"]) != 0 || updates["page"][0] != "4" || updates["Genres"][1] != "drama" {
+		t.Error("unexpected updates", updates)
+	}
+
+	if updates := c.queryUpdates(); updates != nil {
+		t.Error("expecting updates only once, received", updates)
+	}
+
+	c.bindQuery(url.Values{"page": {"many"}})
+
+	if filter.Paging.Page != 4 {
+		t.Error("invalid values must be ignored, page", filter.Paging.Page)
+	}
+}
+
+func TestServer_QueryFromFirstRequest(t *testing.T) {
+	s := NewServer()
+	filter := &queryFilter{}
+
+	lc := NewLiveComponent("Filter", filter)
+	lc.log = s.Log
+
+	lr, err := s.HandleFirstRequestWithQuery(lc, PageContent{}, url.Values{"q": {"dune"}, "page": {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.Search != "dune" || filter.Paging.Page != 2 {
+		t.Fatal("query not bound on first request", filter)
+	}
+
+	session := s.Wire.GetSession(lr.Session)
+
+	filter.Paging.Page = 3
+	if err := session.LiveRenderComponent(lc, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-session.OutChannel:
+			if msg.Type != EventLiveQuery {
+				continue
+			}
+
+			var query map[string][]string
+			if err := json.Unmarshal([]byte(msg.Message), &query); err != nil {
+				t.Fatal(err)
+			}
+
+			if query["page"][0] != "3" || query["q"][0] != "dune" {
+				t.Error("unexpected query message", query)
+			}
+			return
+		case <-timeout:
+			t.Fatal("query message not sent")
+		}
+	}
+}
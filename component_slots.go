@@ -0,0 +1,113 @@
+package golive
+
+import (
+	"bytes"
+	"html/template"
+	"reflect"
+)
+
+// slotContent is the content of a slot, filled by the parent with the
+// fill function of templates. Templates pass slots to children as
+// render arguments, named as the slot, and children place them with the
+// slot function:
+//
+//	{{ render .Card "header" (fill "card-header") }}
+//	{{ define "card-header" }}<h1 go-live-click="Open">{{ .Title }}</h1>{{ end }}
+//
+//	<div class="card">{{ slot "header" }}</div>
+//
+// The defined template is rendered with the data of the parent, and its
+// elements belong to the parent, so their events invoke the parent
+// methods. Definitions go after the root element of the template.
+type slotContent template.HTML
+
+var slotContentType = reflect.TypeOf(slotContent(""))
+
+type componentSlots struct {
+	content map[string]slotContent
+}
+
+// FillSlot renders the template defined with name in the template of the
+// component, to be passed as slot to a child.
+func (l *LiveComponent) FillSlot(name string) slotContent {
+	var b bytes.Buffer
+
+	if err := l.renderer.template.ExecuteTemplate(&b, name, l.component); err != nil {
+		l.log(LogError, "fill slot: execute template", logEx{"name": l.Name, "slot": name, "error": err})
+		return ""
+	}
+
+	root, err := nodeFromString(b.String())
+	if err != nil {
+		l.log(LogError, "fill slot: parse", logEx{"name": l.Name, "slot": name, "error": err})
+		return ""
+	}
+
+	// The elements of the slot are attributed to the parent
+	for _, node := range nodeChildrenElements(root) {
+		if getAttribute(node, ComponentIdAttrKey) == nil {
+			addNodeAttribute(node, ComponentIdAttrKey, l.Name)
+		}
+	}
+
+	rendered, err := renderInnerHTML(root)
+	if err != nil {
+		l.log(LogError, "fill slot: render", logEx{"name": l.Name, "slot": name, "error": err})
+		return ""
+	}
+
+	return slotContent(rendered)
+}
+
+// Slot returns the content of the slot passed by the parent in the last
+// render, empty if it was not passed.
+func (l *LiveComponent) Slot(name string) template.HTML {
+	return template.HTML(l.slots.content[name])
+}
+
+// splitSlots separates the slots from the props of render arguments.
+func splitSlots(args []reflect.Value) ([]reflect.Value, map[string]slotContent) {
+	var slots map[string]slotContent
+	props := make([]reflect.Value, 0, len(args))
+
+	for i := 0; i+1 < len(args); i += 2 {
+		value := indirectInterface(args[i+1])
+
+		if value.IsValid() && value.Type() == slotContentType {
+			name, ok := indirectInterface(args[i]).Interface().(string)
+
+			if ok {
+				if slots == nil {
+					slots = make(map[string]slotContent)
+				}
+
+				slots[name] = value.Interface().(slotContent)
+				continue
+			}
+		}
+
+		props = append(props, args[i], args[i+1])
+	}
+
+	if len(args)%2 != 0 {
+		props = append(props, args[len(args)-1])
+	}
+
+	return props, slots
+}
+
+// setSlots replaces the slots of the component, reporting if any of them
+// changed.
+func (l *LiveComponent) setSlots(slots map[string]slotContent) bool {
+	changed := len(slots) != len(l.slots.content)
+
+	for name, content := range slots {
+		if previous, ok := l.slots.content[name]; !ok || previous != content {
+			changed = true
+		}
+	}
+
+	l.slots.content = slots
+
+	return changed
+}
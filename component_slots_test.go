@@ -0,0 +1,121 @@
+package golive
+
+import (
+	"strings"
+	"testing"
+)
+
+type slotsCard struct {
+	LiveComponentWrapper
+	Open bool
+}
+
+func (c *slotsCard) Toggle() {
+	c.Open = !c.Open
+}
+
+func (c *slotsCard) TemplateHandler(_ *LiveComponent) string {
+	return `<div><header go-live-click="Toggle">{{ slot "header" }}</header><section>{{ slot "body" }}</section></div>`
+}
+
+type slotsPage struct {
+	LiveComponentWrapper
+	Title  string
+	Other  string
+	Name   string `golive:"bind"`
+	Card   *LiveComponent
+	Opened int
+}
+
+func (p *slotsPage) Select() {
+	p.Opened++
+}
+
+func (p *slotsPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div><p>{{ .Other }}</p>{{ render .Card "header" (fill "page-header") "body" (fill "page-body") }}</div>` +
+		`{{ define "page-header" }}<h1 go-live-click="Select">{{ .Title }}</h1>{{ end }}` +
+		`{{ define "page-body" }}<input go-live-input="Name"/>{{ end }}`
+}
+
+func TestComponent_RenderSlots(t *testing.T) {
+	card := &slotsCard{}
+	page := &slotsPage{Title: "Dune", Name: "Paul", Card: NewLiveComponent("Card", card)}
+
+	c := NewLiveComponent("Page", page)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(rendered, `>Dune</h1>`) || !strings.Contains(rendered, `value="Paul"`) {
+		t.Fatal("slots not rendered with the parent data", rendered)
+	}
+
+	// The elements of the slots belong to the parent, the others to the child
+	if !c.IsMethodExposed("Select") || page.Card.IsMethodExposed("Select") {
+		t.Error("slot method not exposed by the parent only")
+	}
+
+	if !page.Card.IsMethodExposed("Toggle") || c.IsMethodExposed("Toggle") {
+		t.Error("child method not exposed by the child only")
+	}
+
+	root, err := nodeFromString(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, node := range getAllChildrenRecursive(root) {
+		cid, _ := componentIDFromNode(node)
+
+		switch node.Data {
+		case "h1", "input":
+			if cid != c.Name {
+				t.Errorf("%s attributed to %s, expected %s", node.Data, cid, c.Name)
+			}
+		case "header", "section":
+			if cid != page.Card.Name {
+				t.Errorf("%s attributed to %s, expected %s", node.Data, cid, page.Card.Name)
+			}
+		}
+	}
+
+	page.Other = "changed"
+	if _, err := c.LiveRender(); err != nil {
+		t.Fatal(err)
+	}
+
+	page.Title = "Emma"
+	d, err := c.LiveRender()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, instruction := range d.instructions {
+		if strings.Contains(instruction.content, "Emma") {
+			found = true
+		}
+	}
+
+	if !found || !strings.Contains(page.Card.renderer.state.text, ">Emma</h1>") {
+		t.Error("slot changes not rendered", d.instructions)
+	}
+
+	// The child renders itself with the slots of the last parent render
+	card.Open = true
+	if _, err := page.Card.LiveRender(); err != nil {
+		t.Fatal(err)
+	}
+
+	text := page.Card.renderer.state.text
+	if !strings.Contains(text, ">Emma</h1>") || !strings.Contains(text, `value="Paul"`) {
+		t.Error("slots lost in the child render", text)
+	}
+}
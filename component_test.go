@@ -1,7 +1,10 @@
 package golive
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -223,3 +226,286 @@ func TestComponent_ComponentSignTemplate(t *testing.T) {
 
 	fmt.Println(c.renderer.templateString)
 }
+
+type Row struct {
+	LiveComponentWrapper
+	Label string
+}
+
+func (r *Row) TemplateHandler(_ *LiveComponent) string {
+	return `<span>{{ .Label }}</span>`
+}
+
+func newRow(label string) *LiveComponent {
+	return NewLiveComponent("Row", &Row{Label: label})
+}
+
+type RowGroup struct {
+	Footer *LiveComponent
+}
+
+type Table struct {
+	LiveComponentWrapper
+	Rows   []*LiveComponent
+	ByName map[string]*LiveComponent
+	Group  RowGroup
+}
+
+func (tb *Table) TemplateHandler(_ *LiveComponent) string {
+	return `<div>
+		{{ range .Rows }}{{ render . }}{{ end }}
+		{{ range .ByName }}{{ render . }}{{ end }}
+		{{ render .Group.Footer }}
+	</div>`
+}
+
+func TestComponent_ChildrenInCollections(t *testing.T) {
+	table := &Table{
+		Rows:   []*LiveComponent{newRow("a"), newRow("b")},
+		ByName: map[string]*LiveComponent{"c": newRow("c")},
+		Group:  RowGroup{Footer: newRow("footer")},
+	}
+
+	c := NewLiveComponent("Table", table)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Mount(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.children) != 4 {
+		t.Fatal("expecting 4 children, found", len(c.children))
+	}
+
+	for _, child := range c.children {
+		if !child.IsMounted {
+			t.Error("child not mounted", child.Name)
+		}
+
+		if c.findComponentByID(child.Name) != child {
+			t.Error("child not found by id", child.Name)
+		}
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	removed := table.Rows[0]
+	added := newRow("d")
+	table.Rows = []*LiveComponent{table.Rows[1], added}
+
+	if _, err := c.LiveRender(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !removed.Exited {
+		t.Error("removed child should be killed")
+	}
+
+	if !added.IsCreated || !added.IsMounted {
+		t.Error("added child should be created and mounted")
+	}
+
+	if c.findComponentByID(added.Name) != added || c.findComponentByID(removed.Name) != nil {
+		t.Error("children not updated")
+	}
+}
+
+type PanicRow struct {
+	Row
+}
+
+func (r *PanicRow) Mounted(_ *LiveComponent) {
+	panic("mounted")
+}
+
+func TestComponent_ChildrenFailingToStart(t *testing.T) {
+	table := &Table{Rows: []*LiveComponent{newRow("a")}, Group: RowGroup{Footer: newRow("footer")}}
+
+	c := NewLiveComponent("Table", table)
+	c.log = func(int, string, map[string]interface{}) {}
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Mount(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	panicking := NewLiveComponent("Row", &PanicRow{Row{Label: "panic"}})
+	broken := NewLiveComponent("Row", &limitForm{})
+	added := newRow("b")
+	table.Rows = append(table.Rows, panicking, broken, added)
+
+	if _, err := c.LiveRender(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !panicking.Exited || !broken.Exited {
+		t.Error("children failing to start not discarded")
+	}
+
+	if !added.IsMounted {
+		t.Error("children after the failing ones not started")
+	}
+
+	rendered, err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(rendered, "panic") || !strings.Contains(rendered, ">b</span>") {
+		t.Error("unexpected render", rendered)
+	}
+
+	if err := c.Kill(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type PetUpdate struct {
+	Name  string
+	Age   int `json:"years"`
+	Awake bool
+}
+
+type PetMethods struct {
+	Pet
+	Calls []string
+}
+
+func (p *PetMethods) SetAge(age int) {
+	p.Age = age
+}
+
+func (p *PetMethods) Rename(name string) error {
+	if name == "" {
+		return errors.New("empty name")
+	}
+	p.Name = name
+	return nil
+}
+
+func (p *PetMethods) Update(u PetUpdate, e *DOMEvent) {
+	p.Name, p.Age, p.Awake = u.Name, u.Age, u.Awake
+	p.Calls = append(p.Calls, e.KeyCode)
+}
+
+func (p *PetMethods) Many(data map[string]string, awake bool, age int) {
+	p.Awake, p.Age = awake, age
+	p.Calls = append(p.Calls, data["0"])
+}
+
+func (p *PetMethods) Unsupported(_ chan int) {}
+
+func TestLiveComponent_InvokeMethodInPathTyped(t *testing.T) {
+	pet := &PetMethods{}
+	c := NewLiveComponent("pet", pet)
+
+	if err := c.InvokeMethodInPath("SetAge", map[string]string{"index": "3"}, nil); err != nil {
+		t.Error(err)
+	}
+
+	if pet.Age != 3 {
+		t.Error("int argument not decoded, age", pet.Age)
+	}
+
+	err := c.InvokeMethodInPath("Update", map[string]string{
+		"name":  "Catdog",
+		"years": "7",
+		"awake": "true",
+	}, &DOMEvent{KeyCode: "Enter"})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	if pet.Name != "Catdog" || pet.Age != 7 || !pet.Awake || pet.Calls[0] != "Enter" {
+		t.Error("struct argument not decoded", pet.Pet)
+	}
+
+	if err := c.InvokeMethodInPath("Many", map[string]string{"0": "false", "1": "9"}, nil); err != nil {
+		t.Error(err)
+	}
+
+	if pet.Awake || pet.Age != 9 || pet.Calls[1] != "false" {
+		t.Error("positional arguments not decoded", pet.Pet)
+	}
+}
+
+func TestLiveComponent_InvokeMethodInPathErrors(t *testing.T) {
+	c := NewLiveComponent("pet", &PetMethods{})
+
+	if err := c.InvokeMethodInPath("Rename", map[string]string{"name": ""}, nil); err == nil || err.Error() != "empty name" {
+		t.Error("expecting the method error, received", err)
+	}
+
+	if err := c.InvokeMethodInPath("SetAge", map[string]string{"age": "old"}, nil); err == nil {
+		t.Error("expecting a decode error")
+	}
+
+	if err := c.InvokeMethodInPath("SetAge", nil, nil); err == nil {
+		t.Error("expecting a missing argument error")
+	}
+
+	if err := c.InvokeMethodInPath("Unsupported", nil, nil); !errors.Is(err, ErrUnsupportedMethodSignature) {
+		t.Error("expecting unsupported signature error, received", err)
+	}
+}
+
+type PetForm struct {
+	Name     string
+	Age      int `json:"years"`
+	Awake    bool
+	Vaccined bool
+	Toys     []string
+}
+
+type PetFormMethods struct {
+	PetMethods
+	Form   PetForm
+	Values url.Values
+}
+
+func (p *PetFormMethods) Save(f PetForm, values url.Values) {
+	p.Form, p.Values = f, values
+}
+
+func TestLiveComponent_InvokeMethodWithForm(t *testing.T) {
+	pet := &PetFormMethods{}
+	c := NewLiveComponent("pet", pet)
+
+	form := map[string][]string{
+		"name":  {"Catdog"},
+		"years": {""},
+		"awake": {"on"},
+		"toys":  {"ball", "bone"},
+	}
+
+	if err := c.InvokeMethodWithForm("Save", form, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f := pet.Form
+	if f.Name != "Catdog" || f.Age != 0 || !f.Awake || f.Vaccined || len(f.Toys) != 2 || f.Toys[1] != "bone" {
+		t.Error("form not decoded", f)
+	}
+
+	if pet.Values.Get("name") != "Catdog" {
+		t.Error("form values not passed", pet.Values)
+	}
+
+	if err := c.InvokeMethodWithForm("Save", map[string][]string{"years": {"old"}}, nil); err == nil {
+		t.Error("expecting a decode error")
+	}
+}
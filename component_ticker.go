@@ -0,0 +1,76 @@
+package golive
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Every calls fn every interval, updating the component after each call,
+// until the component is killed or the returned function is called. fn
+// runs in the goroutine of the page events, where the session renders the
+// component, and ticks are skipped while the previous call is pending. It
+// is usually called in the Mounted hook:
+//
+//	func (c *Clock) Mounted(l *golive.LiveComponent) {
+//		l.Every(time.Second, func() {
+//			c.ActualTime = time.Now().Format(time.RFC3339)
+//		})
+//	}
+//
+// The browser can poll methods too, only while the element is visible,
+// with the go-live-poll attribute and go-live-poll-interval milliseconds:
+//
+//	<div go-live-poll="Refresh" go-live-poll-interval="5000"></div>
+func (l *LiveComponent) Every(interval time.Duration, fn func()) (stop func()) {
+	if interval <= 0 {
+		l.log(LogWarn, "every: interval not positive", logEx{"name": l.Name, "interval": interval})
+		return func() {}
+	}
+
+	ctx := l.Ctx()
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	var pending int32
+
+	tick := func() {
+		defer atomic.StoreInt32(&pending, 0)
+
+		// Killed or stopped while waiting the page
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		default:
+		}
+
+		fn()
+		l.Update()
+	}
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				if atomic.CompareAndSwapInt32(&pending, 0, 1) {
+					l.dispatch(tick)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package golive
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLiveComponent_Every(t *testing.T) {
+	c := NewLiveComponent("Item", &batchItem{})
+	c.log = func(int, string, map[string]interface{}) {}
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var ticks, stoppedTicks int32
+
+	c.Every(time.Millisecond, func() {
+		atomic.AddInt32(&ticks, 1)
+	})
+
+	stop := c.Every(time.Millisecond, func() {
+		atomic.AddInt32(&stoppedTicks, 1)
+	})
+	stop()
+	stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&ticks) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("ticker not called")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := c.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tick may be running while killing
+	time.Sleep(10 * time.Millisecond)
+	killed := atomic.LoadInt32(&ticks)
+	time.Sleep(20 * time.Millisecond)
+
+	if atomic.LoadInt32(&ticks) != killed {
+		t.Error("ticker called after kill")
+	}
+
+	if atomic.LoadInt32(&stoppedTicks) > 1 {
+		t.Error("ticker called after stop", stoppedTicks)
+	}
+}
+
+type pageClock struct {
+	LiveComponentWrapper
+	Ticks  int
+	ticked chan int
+}
+
+func (c *pageClock) Mounted(l *LiveComponent) {
+	l.Every(time.Millisecond, func() {
+		c.Ticks++
+
+		select {
+		case c.ticked <- c.Ticks:
+		default:
+		}
+	})
+}
+
+func (c *pageClock) Reset() {
+	c.Ticks = 0
+}
+
+func (c *pageClock) TemplateHandler(_ *LiveComponent) string {
+	return `<p go-live-click="Reset">{{ .Ticks }}</p>`
+}
+
+func TestLiveComponent_EveryOnPage(t *testing.T) {
+	server := NewServer()
+	server.Log = func(int, string, map[string]interface{}) {}
+
+	clock := &pageClock{ticked: make(chan int)}
+	lc := NewLiveComponent("Clock", clock)
+	lc.log = server.Log
+
+	if _, err := server.HandleFirstRequest(lc, PageContent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ticks run with the renders of the session, without races
+	for ticks := 0; ticks < 3; {
+		select {
+		case ticks = <-clock.ticked:
+		case <-time.After(5 * time.Second):
+			t.Fatal("ticker not called")
+		}
+	}
+
+	var session *Session
+	server.Wire.Sessions.Range(func(_ string, s *Session) bool {
+		session = s
+		return false
+	})
+
+	// The browser events too
+	for i := 0; i < 20; i++ {
+		if err := session.IngestMessage(BrowserEvent{Name: EventLiveMethod, ComponentID: lc.Name, MethodName: "Reset"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stop := lc.Every(0, func() {
+		t.Error("ticker called with an interval not positive")
+	})
+	stop()
+}
+
+type pollFeed struct {
+	LiveComponentWrapper
+}
+
+func (f *pollFeed) Refresh() {}
+
+func (f *pollFeed) TemplateHandler(_ *LiveComponent) string {
+	return `<div go-live-poll="Refresh" go-live-poll-interval="5000"></div>`
+}
+
+func TestLiveComponent_PollExposed(t *testing.T) {
+	c := NewLiveComponent("Feed", &pollFeed{})
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsMethodExposed("Refresh") {
+		t.Error("polled method not exposed")
+	}
+}
@@ -0,0 +1,389 @@
+package golive
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUploadNotFound  = errors.New("upload not found")
+	ErrUploadTooLarge  = errors.New("upload too large")
+	ErrUploadType      = errors.New("upload type not accepted")
+	ErrUploadChunk     = errors.New("upload chunk out of order")
+	ErrUploadsInFlight = errors.New("too many uploads in progress")
+	ErrUploadStalled   = errors.New("upload stalled")
+)
+
+// DefaultUploadMaxSize is the size limit of uploads bound without the
+// go-live-upload-max-size attribute.
+const DefaultUploadMaxSize int64 = 10 << 20
+
+// MaxUploadsInFlight is how many uploads a component receives at the same
+// time, each one has its temporary file.
+const MaxUploadsInFlight = 4
+
+// DefaultUploadIdleTimeout is how long an upload waits for its next chunk
+// before being aborted.
+const DefaultUploadIdleTimeout = time.Minute
+
+// UploadLimits of a go-live-upload input, taken from its accept and
+// go-live-upload-max-size attributes when rendered.
+type UploadLimits struct {
+	MaxSize int64
+	Accept  []string
+}
+
+// accepts checks the name and type of a file against the accept list,
+// which has the format of the accept attribute: extensions as ".png",
+// types as "image/png" or "image/*".
+func (ul UploadLimits) accepts(name, contentType string) bool {
+	if len(ul.Accept) == 0 {
+		return true
+	}
+
+	name = strings.ToLower(name)
+	contentType = strings.ToLower(contentType)
+
+	for _, accept := range ul.Accept {
+		accept = strings.ToLower(strings.TrimSpace(accept))
+
+		switch {
+		case strings.HasPrefix(accept, "."):
+			if strings.HasSuffix(name, accept) {
+				return true
+			}
+		case strings.HasSuffix(accept, "/*"):
+			if strings.HasPrefix(contentType, accept[:len(accept)-1]) {
+				return true
+			}
+		case accept == contentType:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Upload is the progress of a file uploaded to a component, available in
+// templates through the uploads function, as in
+// {{ range uploads "SaveAvatar" }}{{ .Name }} {{ .Progress }}%{{ end }}
+type Upload struct {
+	ID       string
+	Method   string
+	Name     string
+	Type     string
+	Size     int64
+	Received int64
+	Done     bool
+	Error    string
+}
+
+// Progress is the percentage of the file received.
+func (u Upload) Progress() int {
+	if u.Size == 0 {
+		return 100
+	}
+	return int(u.Received * 100 / u.Size)
+}
+
+// UploadedFile is a complete upload, received by the method bound with
+// go-live-upload as *UploadedFile, or as io.Reader of its content. The
+// temporary file in Path is removed after the method returns.
+type UploadedFile struct {
+	Name string
+	Type string
+	Size int64
+	Path string
+}
+
+// Open opens the temporary file with the uploaded content.
+func (f *UploadedFile) Open() (*os.File, error) {
+	return os.Open(f.Path)
+}
+
+// BrowserUpload is the upload part of a BrowserEvent. The first event of
+// an upload has the file description, the following ones the chunks of
+// its content.
+type BrowserUpload struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Size   int64  `json:"size"`
+	Offset int64  `json:"offset"`
+	Data   []byte `json:"data"`
+}
+
+type upload struct {
+	Upload
+	data map[string]string
+	file *os.File
+	idle *time.Timer
+}
+
+// componentUploads are the uploads of a component, their fields are
+// guarded by mu.
+type componentUploads struct {
+	mu      sync.Mutex
+	uploads []*upload
+
+	// idleTimeout is DefaultUploadIdleTimeout when zero
+	idleTimeout time.Duration
+}
+
+// Uploads returns the progress of the uploads to the method.
+func (l *LiveComponent) Uploads(method string) []Upload {
+	l.uploads.mu.Lock()
+	defer l.uploads.mu.Unlock()
+
+	uploads := make([]Upload, 0)
+	for _, u := range l.uploads.uploads {
+		if u.Method == method {
+			uploads = append(uploads, u.Upload)
+		}
+	}
+
+	return uploads
+}
+
+// startUpload begins receiving a file for the method, replacing the
+// finished uploads to the same method.
+func (l *LiveComponent) startUpload(method string, data map[string]string, bu *BrowserUpload) error {
+	if bu == nil || bu.ID == "" {
+		return fmt.Errorf("start upload: %w", ErrUploadNotFound)
+	}
+
+	l.uploads.mu.Lock()
+
+	inFlight := 0
+	uploads := l.uploads.uploads[:0]
+	for _, u := range l.uploads.uploads {
+		if !u.Done && u.Error == "" {
+			inFlight++
+		}
+
+		if u.Method != method || (!u.Done && u.Error == "") {
+			uploads = append(uploads, u)
+		}
+	}
+	l.uploads.uploads = uploads
+
+	if inFlight >= MaxUploadsInFlight {
+		l.uploads.mu.Unlock()
+		return fmt.Errorf("%s: %w", bu.Name, ErrUploadsInFlight)
+	}
+
+	u := &upload{
+		Upload: Upload{
+			ID:     bu.ID,
+			Method: method,
+			Name:   bu.Name,
+			Type:   bu.Type,
+			Size:   bu.Size,
+		},
+		data: data,
+	}
+
+	l.uploads.uploads = append(uploads, u)
+	l.uploads.mu.Unlock()
+
+	limits := l.uploadLimits(method)
+
+	if bu.Size < 0 || bu.Size > limits.MaxSize {
+		return l.failUpload(u, fmt.Errorf("%s: %w, limit of %d bytes", bu.Name, ErrUploadTooLarge, limits.MaxSize))
+	}
+
+	if !limits.accepts(bu.Name, bu.Type) {
+		return l.failUpload(u, fmt.Errorf("%s: %w", bu.Name, ErrUploadType))
+	}
+
+	file, err := os.CreateTemp("", "golive-upload-*")
+	if err != nil {
+		return l.failUpload(u, fmt.Errorf("create temp file: %w", err))
+	}
+
+	l.uploads.mu.Lock()
+	u.file = file
+	complete := u.Size == 0
+	if complete {
+		u.Done = true
+	} else {
+		u.idle = time.AfterFunc(l.uploadIdleTimeout(), func() {
+			l.stallUpload(u)
+		})
+	}
+	l.uploads.mu.Unlock()
+
+	if complete {
+		return l.completeUpload(u)
+	}
+
+	return nil
+}
+
+func (l *LiveComponent) uploadIdleTimeout() time.Duration {
+	if l.uploads.idleTimeout > 0 {
+		return l.uploads.idleTimeout
+	}
+
+	return DefaultUploadIdleTimeout
+}
+
+// stallUpload aborts the upload when it is still waiting for its chunks.
+func (l *LiveComponent) stallUpload(u *upload) {
+	l.uploads.mu.Lock()
+	if u.Done || u.Error != "" {
+		l.uploads.mu.Unlock()
+		return
+	}
+
+	l.failUploadLocked(u, fmt.Errorf("%s: %w", u.Name, ErrUploadStalled))
+	l.uploads.mu.Unlock()
+
+	l.log(LogWarn, "upload stalled", logEx{"name": l.Name, "upload": u.Name})
+
+	if l.Ctx().Err() == nil {
+		l.Update()
+	}
+}
+
+// receiveUploadChunk writes a chunk of the file, invoking the method once
+// the file is complete. Chunks of failed uploads are ignored.
+func (l *LiveComponent) receiveUploadChunk(bu *BrowserUpload) error {
+	if bu == nil {
+		return fmt.Errorf("upload chunk: %w", ErrUploadNotFound)
+	}
+
+	l.uploads.mu.Lock()
+	u := l.findUpload(bu.ID)
+
+	if u == nil {
+		l.uploads.mu.Unlock()
+		return fmt.Errorf("upload chunk %s: %w", bu.ID, ErrUploadNotFound)
+	}
+
+	if u.Error != "" || u.Done {
+		l.uploads.mu.Unlock()
+		return nil
+	}
+
+	var err error
+	switch {
+	case bu.Offset != u.Received:
+		err = fmt.Errorf("%s: %w, offset %d", u.Name, ErrUploadChunk, bu.Offset)
+	case u.Received+int64(len(bu.Data)) > u.Size:
+		err = fmt.Errorf("%s: %w, more than %d bytes", u.Name, ErrUploadTooLarge, u.Size)
+	default:
+		if _, writeErr := u.file.Write(bu.Data); writeErr != nil {
+			err = fmt.Errorf("write upload: %w", writeErr)
+		}
+	}
+
+	if err != nil {
+		l.failUploadLocked(u, err)
+		l.uploads.mu.Unlock()
+		return err
+	}
+
+	u.Received += int64(len(bu.Data))
+	u.idle.Reset(l.uploadIdleTimeout())
+
+	complete := u.Received == u.Size
+	if complete {
+		u.Done = true
+		u.idle.Stop()
+	}
+	l.uploads.mu.Unlock()
+
+	if complete {
+		return l.completeUpload(u)
+	}
+
+	return nil
+}
+
+// completeUpload invokes the method with the uploaded file and removes
+// the temporary file after it. The upload is already marked as done, the
+// file is not touched by other goroutines anymore.
+func (l *LiveComponent) completeUpload(u *upload) error {
+	path := u.file.Name()
+
+	defer os.Remove(path)
+
+	if err := u.file.Close(); err != nil {
+		return l.failUpload(u, fmt.Errorf("close upload: %w", err))
+	}
+
+	file := &UploadedFile{
+		Name: u.Name,
+		Type: u.Type,
+		Size: u.Size,
+		Path: path,
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return l.failUpload(u, fmt.Errorf("open upload: %w", err))
+	}
+	defer reader.Close()
+
+	err = l.invokeMethod(u.Method, methodInput{data: u.data, file: file, reader: reader})
+	if err != nil {
+		l.uploads.mu.Lock()
+		u.Error = err.Error()
+		l.uploads.mu.Unlock()
+	}
+
+	return err
+}
+
+func (l *LiveComponent) failUpload(u *upload, err error) error {
+	l.uploads.mu.Lock()
+	defer l.uploads.mu.Unlock()
+
+	l.failUploadLocked(u, err)
+
+	return err
+}
+
+func (l *LiveComponent) failUploadLocked(u *upload, err error) {
+	u.Error = err.Error()
+	u.discard()
+}
+
+// abortUploads discards the files of the uploads in progress.
+func (l *LiveComponent) abortUploads() {
+	l.uploads.mu.Lock()
+	defer l.uploads.mu.Unlock()
+
+	for _, u := range l.uploads.uploads {
+		u.discard()
+	}
+
+	l.uploads.uploads = nil
+}
+
+func (l *LiveComponent) findUpload(id string) *upload {
+	for _, u := range l.uploads.uploads {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (u *upload) discard() {
+	if u.idle != nil {
+		u.idle.Stop()
+	}
+
+	if u.file == nil || u.Done {
+		return
+	}
+
+	_ = u.file.Close()
+	_ = os.Remove(u.file.Name())
+}
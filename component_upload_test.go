@@ -0,0 +1,194 @@
+package golive
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type uploadForm struct {
+	LiveComponentWrapper
+	Avatar   string
+	Path     string
+	Document *UploadedFile
+}
+
+func (u *uploadForm) SaveAvatar(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	u.Avatar = string(b)
+	return err
+}
+
+func (u *uploadForm) SaveDocument(f *UploadedFile) {
+	u.Document = f
+}
+
+func (u *uploadForm) TemplateHandler(_ *LiveComponent) string {
+	return `<div>
+		<input type="file" go-live-upload="SaveAvatar" accept=".png,image/jpeg" go-live-upload-max-size="10" />
+		<input type="file" go-live-upload="SaveDocument" />
+		{{ range uploads "SaveAvatar" }}<span>{{ .Name }} {{ .Progress }}%</span>{{ end }}
+	</div>`
+}
+
+func newUploadComponent(t *testing.T) (*LiveComponent, *uploadForm) {
+	form := &uploadForm{}
+	c := NewLiveComponent("Upload", form)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	return c, form
+}
+
+func TestComponent_UploadChunks(t *testing.T) {
+	c, form := newUploadComponent(t)
+
+	err := c.startUpload("SaveAvatar", nil, &BrowserUpload{ID: "1", Name: "cat.png", Type: "image/png", Size: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.receiveUploadChunk(&BrowserUpload{ID: "1", Offset: 0, Data: []byte("catd")}); err != nil {
+		t.Fatal(err)
+	}
+
+	uploads := c.Uploads("SaveAvatar")
+	if len(uploads) != 1 || uploads[0].Progress() != 50 || uploads[0].Done {
+		t.Fatal("unexpected progress", uploads)
+	}
+
+	if err := c.receiveUploadChunk(&BrowserUpload{ID: "1", Offset: 4, Data: []byte("ogs!")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if form.Avatar != "catdogs!" {
+		t.Error("file not received, received", form.Avatar)
+	}
+
+	if uploads := c.Uploads("SaveAvatar"); !uploads[0].Done || uploads[0].Progress() != 100 {
+		t.Error("upload not done", uploads)
+	}
+
+	err = c.startUpload("SaveDocument", nil, &BrowserUpload{ID: "2", Name: "empty.txt", Size: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if form.Document == nil || form.Document.Name != "empty.txt" {
+		t.Fatal("file not received", form.Document)
+	}
+
+	if _, err := os.Stat(form.Document.Path); !os.IsNotExist(err) {
+		t.Error("temporary file not removed", err)
+	}
+}
+
+func TestComponent_UploadLimits(t *testing.T) {
+	c, _ := newUploadComponent(t)
+
+	err := c.startUpload("SaveAvatar", nil, &BrowserUpload{ID: "1", Name: "cat.png", Type: "image/png", Size: 11})
+	if !errors.Is(err, ErrUploadTooLarge) {
+		t.Error("expecting too large error, received", err)
+	}
+
+	err = c.startUpload("SaveAvatar", nil, &BrowserUpload{ID: "2", Name: "cat.gif", Type: "image/gif", Size: 1})
+	if !errors.Is(err, ErrUploadType) {
+		t.Error("expecting type error, received", err)
+	}
+
+	err = c.startUpload("SaveAvatar", nil, &BrowserUpload{ID: "3", Name: "cat.jpg", Type: "image/jpeg", Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.receiveUploadChunk(&BrowserUpload{ID: "3", Offset: 1, Data: []byte("a")})
+	if !errors.Is(err, ErrUploadChunk) {
+		t.Error("expecting chunk error, received", err)
+	}
+
+	if uploads := c.Uploads("SaveAvatar"); len(uploads) != 1 || uploads[0].Error == "" {
+		t.Error("failed uploads without error", uploads)
+	}
+
+	if err := c.receiveUploadChunk(&BrowserUpload{ID: "3", Offset: 0, Data: []byte("ab")}); err != nil {
+		t.Error("chunks of failed uploads must be ignored, received", err)
+	}
+
+	if err := c.receiveUploadChunk(&BrowserUpload{ID: "4"}); !errors.Is(err, ErrUploadNotFound) {
+		t.Error("expecting not found error, received", err)
+	}
+}
+
+func TestComponent_UploadsInFlight(t *testing.T) {
+	c, _ := newUploadComponent(t)
+
+	for i := 0; i < MaxUploadsInFlight; i++ {
+		id := strconv.Itoa(i)
+
+		err := c.startUpload("SaveDocument", nil, &BrowserUpload{ID: id, Name: id + ".txt", Size: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := c.startUpload("SaveDocument", nil, &BrowserUpload{ID: "next", Name: "next.txt", Size: 4})
+	if !errors.Is(err, ErrUploadsInFlight) {
+		t.Error("expecting in flight error, received", err)
+	}
+
+	if err := c.receiveUploadChunk(&BrowserUpload{ID: "0", Data: []byte("done")}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.startUpload("SaveDocument", nil, &BrowserUpload{ID: "next", Name: "next.txt", Size: 4})
+	if err != nil {
+		t.Error("finished uploads must not count as in flight, received", err)
+	}
+
+	c.abortUploads()
+}
+
+func TestComponent_UploadStalled(t *testing.T) {
+	c, _ := newUploadComponent(t)
+	c.uploads.idleTimeout = 10 * time.Millisecond
+
+	err := c.startUpload("SaveAvatar", nil, &BrowserUpload{ID: "1", Name: "cat.png", Type: "image/png", Size: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.uploads.mu.Lock()
+	path := c.uploads.uploads[0].file.Name()
+	c.uploads.mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if uploads := c.Uploads("SaveAvatar"); uploads[0].Error != "" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("stalled upload not aborted")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("temporary file of stalled upload not removed", err)
+	}
+
+	if err := c.receiveUploadChunk(&BrowserUpload{ID: "1", Data: []byte("catd")}); err != nil {
+		t.Error("chunks of stalled uploads must be ignored, received", err)
+	}
+}
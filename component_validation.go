@@ -0,0 +1,320 @@
+package golive
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidateTagKey is the struct tag with the rules checked after the
+// browser sets a field through go-live-input, e.g.
+// `validate:"required,min=3,max=20"`. The rules are:
+//
+//	required  the field is not the zero value
+//	min=N     strings, slices and maps have at least N elements, numbers
+//	          are at least N
+//	max=N     as min, at most N
+//	email     the string is an email address
+//
+// Rules not supported by the field type fail the creation of the
+// component. Unknown rules are ignored, the tag may be shared with other
+// validation libraries. Templates reach the errors through fieldError and
+// fieldErrors.
+const ValidateTagKey = "validate"
+
+var ErrValidationRule = errors.New("invalid validation rule")
+
+// FieldValidator is implemented by components with their own validation.
+// ValidateField is called with the path of the field set by the browser
+// after the tag rules passed, the error returned is the field error.
+type FieldValidator interface {
+	ValidateField(path string) error
+}
+
+// componentValidation holds the field errors of a component, by path
+type componentValidation struct {
+	mu     sync.RWMutex
+	errors map[string]string
+}
+
+// FieldError returns the error message of the field in path, empty when
+// the field is valid.
+func (l *LiveComponent) FieldError(path string) string {
+	l.validation.mu.RLock()
+	defer l.validation.mu.RUnlock()
+
+	return l.validation.errors[path]
+}
+
+// FieldErrors returns a copy of the field errors of the component by path.
+func (l *LiveComponent) FieldErrors() map[string]string {
+	l.validation.mu.RLock()
+	defer l.validation.mu.RUnlock()
+
+	errs := make(map[string]string, len(l.validation.errors))
+	for path, message := range l.validation.errors {
+		errs[path] = message
+	}
+
+	return errs
+}
+
+// HasFieldErrors reports if any field of the component is invalid.
+func (l *LiveComponent) HasFieldErrors() bool {
+	l.validation.mu.RLock()
+	defer l.validation.mu.RUnlock()
+
+	return len(l.validation.errors) > 0
+}
+
+// SetFieldError sets the error message of the field in path, an empty
+// message clears it. Useful to report errors found in method handlers.
+func (l *LiveComponent) SetFieldError(path string, message string) {
+	l.validation.mu.Lock()
+	defer l.validation.mu.Unlock()
+
+	if message == "" {
+		delete(l.validation.errors, path)
+		return
+	}
+
+	if l.validation.errors == nil {
+		l.validation.errors = map[string]string{}
+	}
+
+	l.validation.errors[path] = message
+}
+
+// ClearFieldErrors removes all the field errors of the component.
+func (l *LiveComponent) ClearFieldErrors() {
+	l.validation.mu.Lock()
+	defer l.validation.mu.Unlock()
+
+	l.validation.errors = nil
+}
+
+// ValidateField checks the field in path against its tag rules and the
+// FieldValidator of the component, updating its field error. It returns
+// if the field is valid.
+func (l *LiveComponent) ValidateField(path string) bool {
+	message := l.validateField(path)
+	l.SetFieldError(path, message)
+
+	return message == ""
+}
+
+func (l *LiveComponent) validateField(path string) string {
+	field, ok := l.structFieldFromPath(path)
+	if ok {
+		v := l.GetFieldFromPath(path)
+
+		if err := validateRules(*v, field.Tag.Get(ValidateTagKey)); err != nil {
+			return err.Error()
+		}
+	}
+
+	if validator, ok := l.component.(FieldValidator); ok {
+		if err := validator.ValidateField(path); err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
+
+// structFieldFromPath returns the struct field where the path ends, if it
+// ends in one.
+func (l *LiveComponent) structFieldFromPath(path string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(l.component)
+
+	var field reflect.StructField
+	found := false
+
+	for _, s := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		found = false
+
+		switch t.Kind() {
+		case reflect.Struct:
+			if field, found = t.FieldByName(s); !found {
+				return field, false
+			}
+			t = field.Type
+		case reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return field, false
+		}
+	}
+
+	return field, found
+}
+
+// checkValidationRules checks the validate tags of the struct fields
+// the browser can reach from t, so invalid rules fail when the component is
+// created instead of being shown to the user.
+func checkValidationRules(t reflect.Type) error {
+	return checkTypeRules(t, map[reflect.Type]bool{})
+}
+
+func checkTypeRules(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || visited[t] {
+		return nil
+	}
+
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Unexported fields are not set by the browser
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		if tag := field.Tag.Get(ValidateTagKey); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				name, arg := splitRule(rule)
+
+				if err := checkRule(field.Type, name, arg); err != nil {
+					return fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+				}
+			}
+		}
+
+		if err := checkTypeRules(field.Type, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRule reports the rules validateRule can not check on values of t.
+func checkRule(t reflect.Type, name string, arg string) error {
+	switch name {
+	case "required":
+	case "min", "max":
+		if _, err := strconv.ParseFloat(arg, 64); err != nil {
+			return fmt.Errorf("%w: %s=%s", ErrValidationRule, name, arg)
+		}
+
+		if !hasSize(t.Kind()) {
+			return fmt.Errorf("%w: %s not supported for %s", ErrValidationRule, name, t)
+		}
+	case "email":
+		if t.Kind() != reflect.String {
+			return fmt.Errorf("%w: email not supported for %s", ErrValidationRule, t)
+		}
+	}
+
+	return nil
+}
+
+func validateRules(v reflect.Value, tag string) error {
+	if tag == "" {
+		return nil
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg := splitRule(rule)
+
+		if err := validateRule(v, name, arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitRule splits a rule as min=3 in its name and argument
+func splitRule(rule string) (string, string) {
+	if i := strings.Index(rule, "="); i >= 0 {
+		return rule[:i], rule[i+1:]
+	}
+
+	return rule, ""
+}
+
+func validateRule(v reflect.Value, name string, arg string) error {
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return errors.New("is required")
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s rule: %s", name, arg)
+		}
+
+		size, unit, ok := valueSize(v)
+		if !ok {
+			return fmt.Errorf("%s rule not supported for %s", name, v.Type())
+		}
+
+		if name == "min" && size < limit {
+			return fmt.Errorf("must be at least %s%s", arg, unit)
+		}
+
+		if name == "max" && size > limit {
+			return fmt.Errorf("must be at most %s%s", arg, unit)
+		}
+	case "email":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("email rule not supported for %s", v.Type())
+		}
+
+		if s := v.String(); s != "" {
+			if a, err := mail.ParseAddress(s); err != nil || a.Address != s {
+				return errors.New("must be an email address")
+			}
+		}
+	}
+
+	return nil
+}
+
+// valueSize is what min and max rules compare, with the unit of the
+// messages
+func valueSize(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+
+	return 0, "", false
+}
+
+// hasSize reports if valueSize supports the kind
+func hasSize(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
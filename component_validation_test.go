@@ -0,0 +1,193 @@
+package golive
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type validationForm struct {
+	LiveComponentWrapper
+	Name     string `validate:"required,min=3"`
+	Email    string `validate:"email"`
+	Age      int    `validate:"min=18,max=130"`
+	Password string
+}
+
+func (v *validationForm) ValidateField(path string) error {
+	if path == "Password" && v.Password == v.Name {
+		return errors.New("must not be the name")
+	}
+	return nil
+}
+
+func (v *validationForm) TemplateHandler(_ *LiveComponent) string {
+	return `<form>
+		<input go-live-input="Name" />
+		<span>{{ fieldError "Name" }}</span>
+		<input go-live-input="Email" />
+	</form>`
+}
+
+func TestComponent_ValidateTagRules(t *testing.T) {
+	c := NewLiveComponent("Validation", &validationForm{})
+
+	cases := []struct {
+		path, value, message string
+	}{
+		{"Name", "", "is required"},
+		{"Name", "ab", "must be at least 3 characters"},
+		{"Name", "abc", ""},
+		{"Email", "nope", "must be an email address"},
+		{"Email", "a@b.com", ""},
+		{"Age", "12", "must be at least 18"},
+		{"Age", "200", "must be at most 130"},
+		{"Age", "30", ""},
+		{"Password", "abc", "must not be the name"},
+		{"Password", "secret", ""},
+	}
+
+	for _, tc := range cases {
+		if err := c.SetValueInPath(tc.value, tc.path); err != nil {
+			t.Fatal(err)
+		}
+
+		if message := c.FieldError(tc.path); message != tc.message {
+			t.Errorf("%s=%q: expecting error %q, received %q", tc.path, tc.value, tc.message, message)
+		}
+	}
+
+	if c.HasFieldErrors() {
+		t.Error("expecting no field errors", c.FieldErrors())
+	}
+}
+
+func TestComponent_ValidationRender(t *testing.T) {
+	c := NewLiveComponent("Validation", &validationForm{Name: "Catdog"})
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = c.SetValueInPath("", "Name")
+
+	rendered, err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(rendered, `aria-invalid="true"`) || !strings.Contains(rendered, ">is required</span>") {
+		t.Error("field error not rendered", rendered)
+	}
+
+	_ = c.SetValueInPath("Catdog", "Name")
+
+	rendered, _ = c.Render()
+
+	if strings.Contains(rendered, "aria-invalid") || strings.Contains(rendered, "is required") {
+		t.Error("field error rendered after fixed", rendered)
+	}
+}
+
+type summaryForm struct {
+	LiveComponentWrapper
+	Name  string `validate:"required"`
+	Email string `validate:"email"`
+}
+
+func (s *summaryForm) TemplateHandler(_ *LiveComponent) string {
+	return `<form>
+		<ul>{{ range $path, $message := fieldErrors }}<li>{{ $path }} {{ $message }}</li>{{ end }}</ul>
+	</form>`
+}
+
+func TestComponent_FieldErrorsTemplate(t *testing.T) {
+	c := NewLiveComponent("Summary", &summaryForm{})
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = c.SetValueInPath("", "Name")
+	_ = c.SetValueInPath("nope", "Email")
+
+	rendered, err := c.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(rendered, ">Email must be an email address</li>") || !strings.Contains(rendered, ">Name is required</li>") {
+		t.Error("field errors not rendered", rendered)
+	}
+}
+
+type unsupportedRuleForm struct {
+	LiveComponentWrapper
+	Awake bool `validate:"min=1"`
+}
+
+type nestedRuleForm struct {
+	LiveComponentWrapper
+	Address struct {
+		Zip int `validate:"email"`
+	}
+}
+
+type limitForm struct {
+	LiveComponentWrapper
+	Name string `validate:"min=three"`
+}
+
+func (f *unsupportedRuleForm) TemplateHandler(_ *LiveComponent) string { return `<form></form>` }
+func (f *nestedRuleForm) TemplateHandler(_ *LiveComponent) string      { return `<form></form>` }
+func (f *limitForm) TemplateHandler(_ *LiveComponent) string           { return `<form></form>` }
+
+func TestComponent_InvalidValidationRules(t *testing.T) {
+	for _, component := range []ComponentLifeTime{&unsupportedRuleForm{}, &nestedRuleForm{}, &limitForm{}} {
+		c := NewLiveComponent("Invalid", component)
+		c.log = NewLoggerBasic().Log
+
+		if err := c.Create(nil); !errors.Is(err, ErrValidationRule) {
+			t.Errorf("%T: expecting validation rule error, received %v", component, err)
+		}
+	}
+}
+
+type foreignRulesForm struct {
+	LiveComponentWrapper
+	Age     int `validate:"gte=0,min=1"`
+	balance struct {
+		Cents int `validate:"email"`
+	}
+}
+
+func (f *foreignRulesForm) TemplateHandler(_ *LiveComponent) string { return `<form></form>` }
+
+func TestComponent_ForeignValidationRules(t *testing.T) {
+	form := &foreignRulesForm{}
+	c := NewLiveComponent("Foreign", form)
+	c.log = NewLoggerBasic().Log
+
+	// Rules of other libraries and fields the browser can not set are left
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetValueInPath("0", "Age"); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.FieldError("Age") != "must be at least 1" {
+		t.Error("known rule not checked", c.FieldErrors())
+	}
+
+	if err := c.SetValueInPath("2", "Age"); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.HasFieldErrors() {
+		t.Error("unknown rule checked", c.FieldErrors())
+	}
+}
@@ -31,7 +31,7 @@ func (l *LiveComponentWrapper) BeforeUnmount(_ *LiveComponent) {
 func (l *LiveComponentWrapper) Commit() {
 	l.Component.log(LogTrace, "Updated", logEx{"name": l.Component.Name})
 
-	if l.Component.life == nil {
+	if l.Component.lifeCycle() == nil {
 		l.Component.log(LogError, "call to commit on unmounted Component", logEx{"name": l.Component.Name})
 		return
 	}
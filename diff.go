@@ -19,6 +19,7 @@ const (
 	RemoveAttr
 	Replace
 	Move
+	Insert
 )
 
 type changeInstruction struct {
@@ -91,7 +92,11 @@ func (d *diff) diffNode(actual, proposed *html.Node) {
 	}
 
 	d.diffNodeAttributes(actual, proposed)
-	d.diffWalk(actual.FirstChild, proposed.FirstChild)
+
+	if !d.diffKeyedChildren(actual, proposed) {
+		d.diffWalk(actual.FirstChild, proposed.FirstChild)
+	}
+
 	d.markNodeDone(proposed)
 }
 
@@ -153,6 +158,187 @@ func (d *diff) diffWalk(actual, proposed *html.Node) {
 	}
 }
 
+// diffKeyedChildren reconciles children identified by the key attribute,
+// moving, inserting and removing them instead of diffing them by
+// position. It returns false when the children are not all keyed.
+func (d *diff) diffKeyedChildren(actual, proposed *html.Node) bool {
+	if _, ok := getLiveUidAttributeValue(actual); !ok {
+		return false
+	}
+
+	actualKeys, actualNodes, ok := keyedChildren(actual)
+	if !ok {
+		return false
+	}
+
+	proposedKeys, proposedNodes, ok := keyedChildren(proposed)
+	if !ok {
+		return false
+	}
+
+	if len(actualKeys) == 0 && len(proposedKeys) == 0 {
+		return false
+	}
+
+	// current simulates the keys order in the browser while
+	// the instructions are applied
+	current := make([]string, 0, len(actualKeys))
+
+	for _, key := range actualKeys {
+		if _, found := proposedNodes[key]; found {
+			current = append(current, key)
+			continue
+		}
+
+		d.instructions = append(d.instructions, changeInstruction{
+			changeType: Remove,
+			element:    actualNodes[key],
+		})
+		d.markNodeDone(actualNodes[key])
+	}
+
+	stable := stableKeys(current, proposedKeys)
+
+	// Going backwards, every placed node has its final next sibling
+	// already in place
+	for i := len(proposedKeys) - 1; i >= 0; i-- {
+		key := proposedKeys[i]
+
+		if stable[key] {
+			continue
+		}
+
+		anchor := len(current)
+		if i+1 < len(proposedKeys) {
+			anchor = indexOfKey(current, proposedKeys[i+1])
+		}
+
+		if actualNode, found := actualNodes[key]; found {
+			from := indexOfKey(current, key)
+			current = append(current[:from], current[from+1:]...)
+
+			if from < anchor {
+				anchor--
+			}
+
+			d.instructions = append(d.instructions, changeInstruction{
+				changeType: Move,
+				element:    actualNode,
+				index:      anchor,
+			})
+		} else {
+			content, _ := renderNodeToString(proposedNodes[key])
+
+			d.instructions = append(d.instructions, changeInstruction{
+				changeType: Insert,
+				element:    actual,
+				content:    content,
+				index:      anchor,
+			})
+			d.markNodeDone(proposedNodes[key])
+		}
+
+		current = append(current[:anchor], append([]string{key}, current[anchor:]...)...)
+	}
+
+	for _, key := range proposedKeys {
+		if actualNode, found := actualNodes[key]; found {
+			d.diffNode(actualNode, proposedNodes[key])
+		}
+	}
+
+	return true
+}
+
+// keyedChildren returns the keys of the children in order, and the nodes by
+// key. It is not ok if there is a relevant child without an unique key.
+func keyedChildren(parent *html.Node) ([]string, map[string]*html.Node, bool) {
+	keys := make([]string, 0)
+	nodes := make(map[string]*html.Node)
+
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		if !nodeRelevant(child) || child.Type == html.CommentNode {
+			continue
+		}
+
+		if !nodeIsElement(child) {
+			return nil, nil, false
+		}
+
+		keyAttr := getAttribute(child, "key")
+		if keyAttr == nil {
+			return nil, nil, false
+		}
+
+		if _, duplicated := nodes[keyAttr.Val]; duplicated {
+			return nil, nil, false
+		}
+
+		keys = append(keys, keyAttr.Val)
+		nodes[keyAttr.Val] = child
+	}
+
+	return keys, nodes, true
+}
+
+// stableKeys returns the keys of the longest subsequence of current that
+// is already in the proposed order, these nodes never need to move.
+func stableKeys(current, proposed []string) map[string]bool {
+	position := make(map[string]int, len(proposed))
+	for i, key := range proposed {
+		position[key] = i
+	}
+
+	// Longest increasing subsequence of the proposed positions
+	tails := make([]int, 0, len(current))
+	previous := make([]int, len(current))
+
+	for i, key := range current {
+		p := position[key]
+
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if position[current[tails[mid]]] < p {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+
+		previous[i] = -1
+		if lo > 0 {
+			previous[i] = tails[lo-1]
+		}
+
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+
+	stable := make(map[string]bool, len(tails))
+	if len(tails) == 0 {
+		return stable
+	}
+
+	for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+		stable[current[i]] = true
+	}
+
+	return stable
+}
+
+func indexOfKey(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
 func (d *diff) forceRenderElementContent(proposed *html.Node) {
 	childrenHTML, _ := renderInnerHTML(proposed)
 
@@ -171,8 +357,16 @@ func (d *diff) diffNodeAttributes(actual, proposed *html.Node) {
 	actualAttrs := AttrMapFromNode(actual)
 	proposedAttrs := AttrMapFromNode(proposed)
 
-	// Now iterate through the attributes in otherEl
-	for name, otherValue := range proposedAttrs {
+	// Iterating the node attributes, instead of the maps, keeps the
+	// instructions in the same order of the template
+	seen := make(map[string]bool, len(proposed.Attr))
+	for _, attr := range proposed.Attr {
+		name, otherValue := attr.Key, proposedAttrs[attr.Key]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
 		value, found := actualAttrs[name]
 		if !found || value != otherValue {
 			d.instructions = append(d.instructions, changeInstruction{
@@ -186,8 +380,10 @@ func (d *diff) diffNodeAttributes(actual, proposed *html.Node) {
 		}
 	}
 
-	for attrName := range actualAttrs {
-		if _, found := proposedAttrs[attrName]; !found {
+	for _, attr := range actual.Attr {
+		attrName := attr.Key
+		if _, found := proposedAttrs[attrName]; !found && !seen[attrName] {
+			seen[attrName] = true
 
 			d.instructions = append(d.instructions, changeInstruction{
 				changeType: RemoveAttr,
@@ -11,9 +11,11 @@ import (
 )
 
 type diffTest struct {
-	template  string
-	diff      *diff
-	component *LiveComponent
+	template    string
+	items       []string
+	checkedList []string
+	diff        *diff
+	component   *LiveComponent
 }
 
 type instructionExpect struct {
@@ -28,6 +30,15 @@ type diffComponent struct {
 	LiveComponentWrapper
 	testTemplate string
 	Check        bool
+	List         []string
+	CheckedList  []string
+}
+
+func (l *diffComponent) Items() []string {
+	if l.Check {
+		return l.CheckedList
+	}
+	return l.List
 }
 
 var reSelectGoliveAttr = regexp.MustCompile(`[ ]?go-live-uid="[a-zA-Z0-9_\-]+"`)
@@ -45,6 +56,8 @@ func newDiffTest(d diffTest) diffTest {
 	c.log = NewLoggerBasic().Log
 
 	dc.testTemplate = d.template
+	dc.List = d.items
+	dc.CheckedList = d.checkedList
 
 	_ = c.Create(nil)
 	_ = c.Mount()
@@ -91,6 +104,10 @@ func (d *diffTest) assert(expectations []instructionExpect, t *testing.T) {
 				t.Error("attributes are different given:", given.attr, "expeted:", expected.attr)
 			}
 
+			if expected.index != nil && given.index != *expected.index {
+				t.Error("index is different given:", given.index, "expeted:", *expected.index)
+			}
+
 			if !reflect.DeepEqual(pathToComponentRoot(given.element), pathToComponentRoot(expected.element)) {
 				t.Error("elements with different elements given:", pathToComponentRoot(given.element), "expeted:", pathToComponentRoot(expected.element))
 			}
@@ -434,3 +451,122 @@ func TestDiff_DiffMultiKey(t *testing.T) {
 	fmt.Println(dt.diff.instructions)
 
 }
+
+const keyedListTemplate = `<ul>{{ range .Items }}<li key="{{ . }}">{{ . }}</li>{{ end }}</ul>`
+
+// applyKeyedInstructions simulates the browser applying the instructions
+// to the keyed children
+func applyKeyedInstructions(keys []string, instructions []changeInstruction) []string {
+	result := append([]string{}, keys...)
+
+	for _, instruction := range instructions {
+		var key string
+
+		switch instruction.changeType {
+		case Remove, Move:
+			key = getAttribute(instruction.element, "key").Val
+			result = append(result[:indexOfKey(result, key)], result[indexOfKey(result, key)+1:]...)
+		case Insert:
+			node, _ := nodeFromString(instruction.content)
+			key = getAttribute(node.FirstChild, "key").Val
+		default:
+			continue
+		}
+
+		if instruction.changeType != Remove {
+			result = append(result[:instruction.index], append([]string{key}, result[instruction.index:]...)...)
+		}
+	}
+
+	return result
+}
+
+func assertKeyedDiff(t *testing.T, dt diffTest, moves int) {
+	given := applyKeyedInstructions(dt.items, dt.diff.instructions)
+
+	if !reflect.DeepEqual(given, dt.checkedList) {
+		t.Error("keyed children in wrong order given:", given, "expected:", dt.checkedList)
+	}
+
+	if n := len(dt.diff.instructionsByType(Move)); n != moves {
+		t.Error("The number of moves are", n, "expected to be", moves)
+	}
+
+	if n := len(dt.diff.instructionsByType(Replace)) + len(dt.diff.instructionsByType(SetInnerHTML)); n != 0 {
+		t.Error("keyed children should not be re-rendered, found", n, "instructions")
+	}
+}
+
+func TestDiff_KeyedPrepend(t *testing.T) {
+	t.Parallel()
+
+	dt := newDiffTest(diffTest{
+		template:    keyedListTemplate,
+		items:       []string{"a", "b", "c"},
+		checkedList: []string{"z", "a", "b", "c"},
+	})
+
+	c := `<li key="z">z</li>`
+	i := 0
+	dt.assert([]instructionExpect{
+		{
+			changeType: Insert,
+			element:    dt.diff.actual.FirstChild,
+			content:    &c,
+			index:      &i,
+		},
+	}, t)
+
+	assertKeyedDiff(t, dt, 0)
+}
+
+func TestDiff_KeyedRemove(t *testing.T) {
+	t.Parallel()
+
+	dt := newDiffTest(diffTest{
+		template:    keyedListTemplate,
+		items:       []string{"a", "b", "c"},
+		checkedList: []string{"a", "c"},
+	})
+
+	dt.assert([]instructionExpect{
+		{
+			changeType: Remove,
+			element:    dt.diff.actual.FirstChild.FirstChild.NextSibling,
+		},
+	}, t)
+
+	assertKeyedDiff(t, dt, 0)
+}
+
+func TestDiff_KeyedReverse(t *testing.T) {
+	t.Parallel()
+
+	dt := newDiffTest(diffTest{
+		template:    keyedListTemplate,
+		items:       []string{"a", "b", "c", "d"},
+		checkedList: []string{"d", "c", "b", "a"},
+	})
+
+	assertKeyedDiff(t, dt, 3)
+}
+
+func TestDiff_KeyedShuffle(t *testing.T) {
+	t.Parallel()
+
+	dt := newDiffTest(diffTest{
+		template:    keyedListTemplate,
+		items:       []string{"a", "b", "c", "d", "e", "f"},
+		checkedList: []string{"c", "a", "g", "b", "f", "e"},
+	})
+
+	assertKeyedDiff(t, dt, 2)
+
+	if n := len(dt.diff.instructionsByType(Insert)); n != 1 {
+		t.Error("The number of inserts are", n, "expected to be 1")
+	}
+
+	if n := len(dt.diff.instructionsByType(Remove)); n != 1 {
+		t.Error("The number of removes are", n, "expected to be 1")
+	}
+}
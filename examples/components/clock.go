@@ -22,16 +22,9 @@ func formattedActualTime() string {
 }
 
 func (c *Clock) Mounted(l *golive.LiveComponent) {
-	go func() {
-		for {
-			if l.Exited {
-				return
-			}
-			c.ActualTime = formattedActualTime()
-			time.Sleep(time.Second)
-			c.Commit()
-		}
-	}()
+	l.Every(time.Second, func() {
+		c.ActualTime = formattedActualTime()
+	})
 }
 
 func (c *Clock) TemplateHandler(_ *golive.LiveComponent) string {
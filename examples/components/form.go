@@ -6,6 +6,14 @@ type Form struct {
 	golive.LiveComponentWrapper
 	Label        string
 	DynamicInput *golive.LiveComponent
+	Submitted    *Subscription
+}
+
+type Subscription struct {
+	Email  string
+	Plan   string
+	Topics []string
+	Terms  bool
 }
 
 func NewForm() *golive.LiveComponent {
@@ -15,8 +23,26 @@ func NewForm() *golive.LiveComponent {
 	})
 }
 
+func (d *Form) Subscribe(s Subscription) {
+	d.Submitted = &s
+}
+
 func (d *Form) TemplateHandler(_ *golive.LiveComponent) string {
 	return `<div>
 		{{ render .DynamicInput }}
+		<form go-live-submit="Subscribe">
+			<input type="email" name="email"/>
+			<label><input type="radio" name="plan" value="free" checked/> Free</label>
+			<label><input type="radio" name="plan" value="pro"/> Pro</label>
+			<select name="topics" multiple>
+				<option value="news">News</option>
+				<option value="releases">Releases</option>
+			</select>
+			<label><input type="checkbox" name="terms"/> Accept terms</label>
+			<button type="submit">Subscribe</button>
+		</form>
+		{{ with .Submitted }}
+			<p>{{ .Email }} subscribed to {{ .Plan }} {{ .Topics }}</p>
+		{{ end }}
 	</div>`
 }
@@ -85,6 +85,7 @@ func (t *Todo) TemplateHandler(_ *golive.LiveComponent) string {
 					<div class="{{ $task.GetClasses }}" key="{{$index}}">
 						<input type="checkbox" go-live-input="Tasks.{{$index}}.Done"></input>
 						<span>{{ $task.Text }}</span>
+						<button go-live-click="TaskDone" go-live-data-index="{{$index}}">Done</button>
 					</div>
 				{{ end }}
 			</div>
@@ -3,6 +3,7 @@ module github.com/brendonmatos/golive
 go 1.18
 
 require (
+	github.com/fasthttp/websocket v1.4.3
 	github.com/gofiber/fiber/v2 v2.2.3
 	github.com/gofiber/websocket/v2 v2.0.2
 	github.com/logrusorgru/aurora/v3 v3.0.0
@@ -11,7 +12,6 @@ require (
 
 require (
 	github.com/andybalholm/brotli v1.0.0 // indirect
-	github.com/fasthttp/websocket v1.4.3 // indirect
 	github.com/klauspost/compress v1.10.7 // indirect
 	github.com/savsgio/gotils v0.0.0-20200608150037-a5f6f5aef16c // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
@@ -15,7 +15,7 @@ var BasePageString = `<!DOCTYPE html>
   </body>
 
   <script type="application/javascript">
-    const GO_LIVE_CONNECTED="go-live-connected",GO_LIVE_COMPONENT_ID="go-live-component-id",EVENT_LIVE_DOM_COMPONENT_ID_KEY="cid",EVENT_LIVE_DOM_INSTRUCTIONS_KEY="i",EVENT_LIVE_DOM_TYPE_KEY="t",EVENT_LIVE_DOM_CONTENT_KEY="c",EVENT_LIVE_DOM_ATTR_KEY="a",EVENT_LIVE_DOM_SELECTOR_KEY="s",EVENT_LIVE_DOM_INDEX_KEY="i",handleChange={"{{ .Enum.DiffSetAttr }}":handleDiffSetAttr,"{{ .Enum.DiffRemoveAttr }}":handleDiffRemoveAttr,"{{ .Enum.DiffReplace }}":handleDiffReplace,"{{ .Enum.DiffRemove }}":handleDiffRemove,"{{ .Enum.DiffSetInnerHTML }}":handleDiffSetInnerHTML,"{{ .Enum.DiffAppend }}":handleDiffAppend,"{{ .Enum.DiffMove }}":handleDiffMove},goLive={server:createConnection(),handlers:[],once:createOnceEmitter(),getLiveComponent(a){return document.querySelector(["*[",GO_LIVE_COMPONENT_ID,"=",a,"]"].join(""))},on(a,b){const c=this.handlers.push({name:a,handler:b});return c-1},findHandler(a){return this.handlers.filter(b=>b.name===a)},emit(a,b){for(const c of this.findHandler(a))c.handler(b)},off(a){this.handlers.splice(a,1)},send(a){goLive.server.send(JSON.stringify(a))},connectChildren(a){const b=a.querySelectorAll("*["+GO_LIVE_COMPONENT_ID+"]");b.forEach(a=>{this.connectElement(a)})},connectElement(a){if(typeof a=="string"){console.warn("is string");return}if(!isElement(a)){console.warn("not element");return}const b=[],c=findLiveClicksFromElement(a);c.forEach(function(a){const c=getComponentIdFromElement(a);a.addEventListener("click",function(b){goLive.send({name:"{{ .Enum.EventLiveMethod }}",component_id:c,method_name:a.getAttribute("go-live-click"),method_data:dataFromElementAttributes(a)})}),b.push(a)});const d=findLiveKeyDownFromElement(a);d.forEach(function(a){const e=getComponentIdFromElement(a),f=a.getAttribute("go-live-keydown"),c=a.attributes;let d=[];for(let a=0;a<c.length;a++)(c[a].name==="go-live-key"||c[a].name.startsWith("go-live-key-"))&&d.push(c[a].value);a.addEventListener("keydown",function(g){const c=String(g.code);let b=!0;if(d.length!==0){b=!1;for(let a=0;a<d.length;a++)if(d[a]===c){b=!0;break}}b&&goLive.send({name:"{{ .Enum.EventLiveMethod }}",component_id:e,method_name:f,method_data:dataFromElementAttributes(a),dom_event:{keyCode:c}})}),b.push(a)});const e=findLiveInputsFromElement(a);e.forEach(function(a){const c=a.getAttribute("type"),d=getComponentIdFromElement(a);a.addEventListener("input",function(e){let b=a.value;c==="checkbox"&&(b=a.checked),goLive.send({name:"{{ .Enum.EventLiveInput }}",component_id:d,key:a.getAttribute("go-live-input"),value:String(b)})}),b.push(a)});for(const a of b)a.setAttribute(GO_LIVE_CONNECTED,!0)},connect(a){const b=goLive.getLiveComponent(a);goLive.connectElement(b),goLive.on("{{ .Enum.EventLiveDom }}",function(b){if(a===b[EVENT_LIVE_DOM_COMPONENT_ID_KEY])for(const c of b[EVENT_LIVE_DOM_INSTRUCTIONS_KEY]){const f=c[EVENT_LIVE_DOM_TYPE_KEY],g=c[EVENT_LIVE_DOM_CONTENT_KEY],h=c[EVENT_LIVE_DOM_ATTR_KEY],d=c[EVENT_LIVE_DOM_SELECTOR_KEY],i=c[EVENT_LIVE_DOM_INDEX_KEY],e=document.querySelector(d);if(!e){console.error("Element not found",d);return}handleChange[f]({content:g,attr:h,index:i},e,a)}})}};goLive.once.on("WS_CONNECTION_OPEN",()=>{goLive.on("{{ .Enum.EventLiveConnectElement }}",a=>{const b=a[EVENT_LIVE_DOM_COMPONENT_ID_KEY];goLive.connect(b)}),goLive.on("{{ .Enum.EventLiveError }}",a=>{console.error("message",a.m),a.m==='{{ index .EnumLiveError ` + "`LiveErrorSessionNotFound`" + `}}'&&window.location.reload(!1)})}),goLive.server.onmessage=a=>{try{const b=JSON.parse(a.data);goLive.emit(b.t,b)}catch(b){console.log("Error",b),console.log("Error message",a.data)}},goLive.server.onopen=()=>{goLive.once.emit("WS_CONNECTION_OPEN")};function createConnection(){const a=[];return window.location.protocol==="https:"?a.push("wss"):a.push("ws"),a.push("://",window.location.host,"/ws"),new WebSocket(a.join(""))}function createOnceEmitter(){const a={},b=(b,c)=>(a[b]={called:c,cbs:[]},a[b]);return{on(d,e){let c=a[d];c||(c=b(d,!1)),c.cbs.push(e)},emit(c,...e){const d=a[c];if(!d){b(c,!0);return}for(const a of d.cbs)a()}}}const findLiveInputsFromElement=a=>a.querySelectorAll(["*[go-live-input]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveClicksFromElement=a=>a.querySelectorAll(["*[go-live-click]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveKeyDownFromElement=a=>a.querySelectorAll(["*[go-live-keydown]:not([",GO_LIVE_CONNECTED,"])"].join("")),dataFromElementAttributes=c=>{const a=c.attributes;let b={};for(let c=0;c<a.length;c++)a[c].name.startsWith("go-live-data-")&&(b[a[c].name.substring(13)]=a[c].value);return b};function getElementChild(b,c){let a=b.firstElementChild;while(c>0){if(!a){console.error("Element not found in path",b);return}if(a=a.nextSibling,a.nodeType!==Node.ELEMENT_NODE)continue;c--}return a}function isElement(a){return typeof HTMLElement=="object"?a instanceof HTMLElement:a&&typeof a=="object"&&a.nodeType===1&&typeof a.nodeName=="string"}function handleDiffSetAttr(c,b){const{attr:a}=c;a.Name==="value"&&b.value?b.value=a.Value:b.setAttribute(a.Name,a.Value)}function handleDiffRemoveAttr(a,b){const{attr:c}=a;b.removeAttribute(c.Name)}function handleDiffReplace(d,a){const{content:e}=d,b=document.createElement("div");b.innerHTML=e;const c=a.parentElement;c.replaceChild(b.firstChild,a),goLive.connectElement(c)}function handleDiffRemove(c,a){const b=a.parentElement;b.removeChild(a)}function handleDiffSetInnerHTML(c,a){let{content:b}=c;if(b===void 0&&(b=""),a.nodeType===Node.TEXT_NODE){a.textContent=b;return}a.innerHTML=b,goLive.connectElement(a)}function handleDiffAppend(c,a){const{content:d}=c,b=document.createElement("div");b.innerHTML=d;const e=b.firstChild;a.appendChild(e),goLive.connectElement(a)}function handleDiffMove(c,a){const b=a.parentNode;b.removeChild(a);const d=getElementChild(b,c.index);b.replaceChild(a,d)}const getComponentIdFromElement=a=>{const b=a.getAttribute("go-live-component-id");return b?b:a.parentElement?getComponentIdFromElement(a.parentElement):void 0}
+    const GO_LIVE_CONNECTED="go-live-connected",GO_LIVE_SCANNED="go-live-scanned",GO_LIVE_COMPONENT_ID="go-live-component-id",EVENT_LIVE_DOM_COMPONENT_ID_KEY="cid",EVENT_LIVE_DOM_INSTRUCTIONS_KEY="i",EVENT_LIVE_DOM_TYPE_KEY="t",EVENT_LIVE_DOM_CONTENT_KEY="c",EVENT_LIVE_DOM_ATTR_KEY="a",EVENT_LIVE_DOM_SELECTOR_KEY="s",EVENT_LIVE_DOM_INDEX_KEY="i",RECONNECT_BASE_DELAY=250,RECONNECT_MAX_DELAY=1e4,TRANSPORT_WEBSOCKET="ws",TRANSPORT_SSE="sse",UPLOAD_CHUNK_SIZE=64*1024,EVENT_BINDING_PREFIX="go-live-on:",DOM_EVENT_PROPERTIES=["key","altKey","ctrlKey","metaKey","shiftKey","button","clientX","clientY","deltaX","deltaY"],handleChange={"{{ .Enum.DiffSetAttr }}":handleDiffSetAttr,"{{ .Enum.DiffRemoveAttr }}":handleDiffRemoveAttr,"{{ .Enum.DiffReplace }}":handleDiffReplace,"{{ .Enum.DiffRemove }}":handleDiffRemove,"{{ .Enum.DiffSetInnerHTML }}":handleDiffSetInnerHTML,"{{ .Enum.DiffAppend }}":handleDiffAppend,"{{ .Enum.DiffMove }}":handleDiffMove,"{{ .Enum.DiffInsert }}":handleDiffInsert},goLive={server:null,transport:TRANSPORT_WEBSOCKET,websocketOpened:!1,reconnectAttempts:0,path:window.location.pathname+window.location.search,handlers:[],once:createOnceEmitter(),getLiveComponent(a){return document.querySelector(["*[",GO_LIVE_COMPONENT_ID,"=",a,"]"].join(""))},on(a,b){const c=this.handlers.push({name:a,handler:b});return c-1},findHandler(a){return this.handlers.filter(b=>b.name===a)},emit(a,b){for(const c of this.findHandler(a))c.handler(b)},off(a){this.handlers.splice(a,1)},send(a){if(!goLive.server||goLive.server.readyState!==WebSocket.OPEN){console.warn("connection not open, message dropped",a);return}goLive.server.send(JSON.stringify(a))},navigate(a){if(!goLive.server||goLive.server.readyState!==WebSocket.OPEN){window.location.assign(a);return}goLive.send({name:"{{ .Enum.EventLiveNavigate }}",url:a})},upload(b,c,a){const d=[Date.now().toString(36),Math.random().toString(36).substring(2)].join("");goLive.send({name:"{{ .Enum.EventLiveUpload }}",component_id:b,method_name:c.getAttribute("go-live-upload"),method_data:dataFromElementAttributes(c),upload:{id:d,name:a.name,type:a.type,size:a.size}});const e=c=>{if(c>=a.size)return;const f=new FileReader;f.onload=()=>{const a=f.result.substring(f.result.indexOf(",")+1);goLive.send({name:"{{ .Enum.EventLiveUploadChunk }}",component_id:b,upload:{id:d,offset:c,data:a}}),e(c+UPLOAD_CHUNK_SIZE)},f.readAsDataURL(a.slice(c,c+UPLOAD_CHUNK_SIZE))};e(0)},connectServer(){const a=goLive.transport===TRANSPORT_SSE?createSSEConnection():createConnection();let b=!1;a.onmessage=a=>{try{const b=JSON.parse(a.data);goLive.emit(b.t,b)}catch(b){console.log("Error",b),console.log("Error message",a.data)}},a.onopen=()=>{b=!0,goLive.reconnectAttempts=0,goLive.websocketOpened=goLive.websocketOpened||goLive.transport===TRANSPORT_WEBSOCKET,goLive.once.emit("WS_CONNECTION_OPEN")},a.onclose=()=>{if(!b&&!goLive.websocketOpened&&goLive.transport===TRANSPORT_WEBSOCKET){probeHTTP().then(a=>{if(!a){goLive.reconnectServer();return}console.warn("websocket unavailable, falling back to sse"),goLive.transport=TRANSPORT_SSE,goLive.connectServer()});return}!b&&goLive.transport===TRANSPORT_SSE&&(console.warn("sse unavailable, retrying websocket"),goLive.transport=TRANSPORT_WEBSOCKET),goLive.reconnectServer()},goLive.server=a},reconnectServer(){const a=Math.min(RECONNECT_MAX_DELAY,RECONNECT_BASE_DELAY*Math.pow(2,goLive.reconnectAttempts));goLive.reconnectAttempts++,setTimeout(()=>goLive.connectServer(),a)},connectChildren(a){const b=a.querySelectorAll("*["+GO_LIVE_COMPONENT_ID+"]");b.forEach(a=>{this.connectElement(a)})},connectElement(a){if(typeof a=="string"){console.warn("is string");return}if(!isElement(a)){console.warn("not element");return}const b=[],c=findLiveClicksFromElement(a);c.forEach(function(a){const c=getComponentIdFromElement(a),d=rateLimitedSend(a);a.addEventListener("click",function(b){d({name:"{{ .Enum.EventLiveMethod }}",component_id:c,method_name:a.getAttribute("go-live-click"),method_data:dataFromElementAttributes(a)})}),b.push(a)});const d=findLivePollsFromElement(a);d.forEach(function(a){const e=getComponentIdFromElement(a),f=parseInt(a.getAttribute("go-live-poll-interval"),10)||1e3;let d=!0,c=null;"IntersectionObserver"in window&&(d=!1,c=new IntersectionObserver(a=>{d=a[a.length-1].isIntersecting}),c.observe(a));const g=setInterval(function(){if(!a.isConnected){clearInterval(g),c&&c.disconnect();return}if(!d||document.visibilityState!=="visible")return;goLive.send({name:"{{ .Enum.EventLiveMethod }}",component_id:e,method_name:a.getAttribute("go-live-poll"),method_data:dataFromElementAttributes(a)})},f);b.push(a)});const e=findLiveKeyDownFromElement(a);e.forEach(function(a){const e=getComponentIdFromElement(a),f=a.getAttribute("go-live-keydown"),g=rateLimitedSend(a),c=a.attributes;let d=[];for(let a=0;a<c.length;a++)(c[a].name==="go-live-key"||c[a].name.startsWith("go-live-key-"))&&d.push(c[a].value);a.addEventListener("keydown",function(h){const c=String(h.code);let b=!0;if(d.length!==0){b=!1;for(let a=0;a<d.length;a++)if(d[a]===c){b=!0;break}}b&&g({name:"{{ .Enum.EventLiveMethod }}",component_id:e,method_name:f,method_data:dataFromElementAttributes(a),dom_event:{keyCode:c}})}),b.push(a)});const f=findLiveSubmitsFromElement(a);f.forEach(function(a){const c=getComponentIdFromElement(a);a.addEventListener("submit",function(b){b.preventDefault(),goLive.send({name:"{{ .Enum.EventLiveSubmit }}",component_id:c,method_name:a.getAttribute("go-live-submit"),method_data:dataFromElementAttributes(a),form_data:dataFromForm(a)})}),b.push(a)});const g=findLiveUploadsFromElement(a);g.forEach(function(a){const c=getComponentIdFromElement(a);a.addEventListener("change",function(b){for(const b of a.files)goLive.upload(c,a,b);a.value=""}),b.push(a)});const h=findLiveLinksFromElement(a);h.forEach(function(a){a.addEventListener("click",function(b){if(b.defaultPrevented||b.button!==0||b.metaKey||b.ctrlKey||b.shiftKey||b.altKey)return;const c=new URL(a.href,window.location.href);if(c.origin!==window.location.origin)return;b.preventDefault(),goLive.navigate(c.pathname+c.search+c.hash)}),b.push(a)});const i=findLiveEventBindingsFromElement(a);i.forEach(function(a){const c=getComponentIdFromElement(a),d=rateLimitedSend(a),e=a.hasAttribute("go-live-prevent");for(const f of a.attributes){if(!f.name.startsWith(EVENT_BINDING_PREFIX))continue;const b=f.name.substring(EVENT_BINDING_PREFIX.length),h=f.value,g=e||b==="drop"||b==="submit";b==="drop"&&a.addEventListener("dragover",a=>a.preventDefault()),a.addEventListener(b,function(b){g&&b.preventDefault(),d({name:"{{ .Enum.EventLiveMethod }}",component_id:c,method_name:h,method_data:dataFromElementAttributes(a),dom_event:domEventFromEvent(b,a)})},{passive:!g})}b.push(a)});const j=findLiveInputsFromElement(a);j.forEach(function(a){const c=a.getAttribute("type"),d=getComponentIdFromElement(a),e=rateLimitedSend(a),f=a.hasAttribute("go-live-lazy")?"change":"input";a.addEventListener(f,function(f){let b=a.value;c==="checkbox"&&(b=a.checked),e({name:"{{ .Enum.EventLiveInput }}",component_id:d,key:a.getAttribute("go-live-input"),value:String(b)})}),b.push(a)});for(const a of b)a.setAttribute(GO_LIVE_CONNECTED,!0)},connect(a){const b=goLive.getLiveComponent(a);goLive.connectElement(b),goLive.on("{{ .Enum.EventLiveDom }}",function(b){if(a===b[EVENT_LIVE_DOM_COMPONENT_ID_KEY])for(const c of b[EVENT_LIVE_DOM_INSTRUCTIONS_KEY]){const f=c[EVENT_LIVE_DOM_TYPE_KEY],g=c[EVENT_LIVE_DOM_CONTENT_KEY],h=c[EVENT_LIVE_DOM_ATTR_KEY],d=c[EVENT_LIVE_DOM_SELECTOR_KEY],i=c[EVENT_LIVE_DOM_INDEX_KEY],e=document.querySelector(d);if(!e){console.error("Element not found",d);return}handleChange[f]({content:g,attr:h,index:i},e,a)}})}};goLive.once.on("WS_CONNECTION_OPEN",()=>{goLive.on("{{ .Enum.EventLiveConnectElement }}",a=>{const b=a[EVENT_LIVE_DOM_COMPONENT_ID_KEY];goLive.connect(b)}),goLive.on("{{ .Enum.EventLiveBatch }}",a=>{for(const b of a.p||[])goLive.emit(b.t,b)}),goLive.on("{{ .Enum.EventLiveNavigate }}",handleNavigate),goLive.on("{{ .Enum.EventLiveQuery }}",handleQuery),goLive.on("{{ .Enum.EventLiveError }}",a=>{console.error("message",a.m),a.m==='{{ index .EnumLiveError ` + "`LiveErrorSessionNotFound`" + `}}'&&window.location.reload(!1)})}),window.addEventListener("popstate",function(b){const a=window.location;if(a.pathname+a.search===goLive.path)return;goLive.navigate(a.pathname+a.search+a.hash)}),goLive.connectServer();function createConnection(){const a=[];return window.location.protocol==="https:"?a.push("wss"):a.push("ws"),a.push("://",window.location.host,"/ws"),new WebSocket(a.join(""))}function probeHTTP(){return fetch("/sse",{method:"POST",credentials:"omit",headers:{"Content-Type":"application/json"},body:"{}"}).then(a=>a.headers.get("{{ .Enum.SSEHeader }}")===TRANSPORT_SSE,()=>!1)}function createSSEConnection(){const c="/sse",b=new EventSource(c);let d=Promise.resolve();const a={readyState:WebSocket.CONNECTING,onopen:null,onmessage:null,onclose:null,send(a){d=d.then(()=>fetch(c,{method:"POST",credentials:"same-origin",headers:{"Content-Type":"application/json"},body:a})).catch(a=>console.error("send error",a))},close(){b.close(),a.readyState=WebSocket.CLOSED}};return b.onopen=()=>{a.readyState=WebSocket.OPEN,a.onopen&&a.onopen()},b.onmessage=b=>{a.onmessage&&a.onmessage(b)},b.onerror=()=>{a.close(),a.onclose&&a.onclose()},a}function createOnceEmitter(){const a={},b=(b,c)=>(a[b]={called:c,cbs:[]},a[b]);return{on(d,e){let c=a[d];if(c||(c=b(d,!1)),c.called){e();return}c.cbs.push(e)},emit(d,...e){const c=a[d];if(!c){b(d,!0);return}if(c.called)return;c.called=!0;for(const a of c.cbs)a()}}}const findLiveInputsFromElement=a=>a.querySelectorAll(["*[go-live-input]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveClicksFromElement=a=>a.querySelectorAll(["*[go-live-click]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLivePollsFromElement=a=>a.querySelectorAll(["*[go-live-poll]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveKeyDownFromElement=a=>a.querySelectorAll(["*[go-live-keydown]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveSubmitsFromElement=a=>a.querySelectorAll(["form[go-live-submit]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveLinksFromElement=a=>a.querySelectorAll(["a[go-live-link]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveUploadsFromElement=a=>a.querySelectorAll(["input[type=file][go-live-upload]:not([",GO_LIVE_CONNECTED,"])"].join("")),findLiveEventBindingsFromElement=a=>{const b=a.querySelectorAll(["*:not([",GO_LIVE_SCANNED,"])"].join(""));return Array.prototype.filter.call(b,a=>{a.setAttribute(GO_LIVE_SCANNED,"");for(const b of a.attributes)if(b.name.startsWith(EVENT_BINDING_PREFIX))return!0;return!1})};function domEventFromEvent(a,c){const b={type:a.type};a.code!==void 0&&(b.keyCode=String(a.code));for(const c of DOM_EVENT_PROPERTIES)a[c]!==void 0&&(b[c]=a[c]);return a.type==="scroll"&&(b.scrollTop=c.scrollTop,b.scrollLeft=c.scrollLeft),c.value!==void 0&&(b.value=String(c.value),b.checked=c.checked===!0),a.dataTransfer&&(b.data=a.dataTransfer.getData("text/plain")),b}function rateLimitedSend(a){const b=parseInt(a.getAttribute("go-live-debounce"),10),c=parseInt(a.getAttribute("go-live-throttle"),10);if(b>0){let a=null;return c=>{clearTimeout(a),a=setTimeout(()=>goLive.send(c),b)}}if(c>0){let b=0,a=null,d=null;return e=>{const f=b+c-Date.now();if(f<=0&&!a){b=Date.now(),goLive.send(e);return}d=e,a||(a=setTimeout(()=>{a=null,b=Date.now(),goLive.send(d)},f))}}return a=>goLive.send(a)}const dataFromForm=b=>{let a={};for(const[c,d]of new FormData(b)){if(typeof d!="string")continue;(a[c]=a[c]||[]).push(d)}return a},dataFromElementAttributes=c=>{const a=c.attributes;let b={};for(let c=0;c<a.length;c++)a[c].name.startsWith("go-live-data-")&&(b[a[c].name.substring(13)]=a[c].value);return b};function getElementChild(a,b){return a.children[b||0]||null}function isElement(a){return typeof HTMLElement=="object"?a instanceof HTMLElement:a&&typeof a=="object"&&a.nodeType===1&&typeof a.nodeName=="string"}function handleNavigate(a){const b=a.m,c=a[EVENT_LIVE_DOM_INSTRUCTIONS_KEY],d=goLive.getLiveComponent(a[EVENT_LIVE_DOM_COMPONENT_ID_KEY]);if(!c||!c.length||!d){window.location.assign(b);return}const f=document.createElement("div");f.innerHTML=c[0][EVENT_LIVE_DOM_CONTENT_KEY];const g=d.parentElement;g.replaceChild(f.firstElementChild,d),goLive.connectElement(g);const e=new URL(b,window.location.href);e.href!==window.location.href&&window.history.pushState({},"",b),goLive.path=e.pathname+e.search}function handleQuery(f){const a=window.location,b=new URLSearchParams(a.search),c=JSON.parse(f.m);for(const a of Object.keys(c)){b.delete(a);for(const d of c[a]||[])b.append(a,d)}const d=b.toString(),e=a.pathname+(d?"?"+d:"")+a.hash;e!==a.pathname+a.search+a.hash&&(window.history.replaceState(window.history.state,"",e),goLive.path=a.pathname+a.search)}function handleDiffSetAttr(c,b){const{attr:a}=c;a.Name==="value"&&b.value?b.value=a.Value:b.setAttribute(a.Name,a.Value)}function handleDiffRemoveAttr(a,b){const{attr:c}=a;b.removeAttribute(c.Name)}function handleDiffReplace(d,a){const{content:e}=d,b=document.createElement("div");b.innerHTML=e;const c=a.parentElement;c.replaceChild(b.firstChild,a),goLive.connectElement(c)}function handleDiffRemove(c,a){const b=a.parentElement;b.removeChild(a)}function handleDiffSetInnerHTML(c,a){let{content:b}=c;if(b===void 0&&(b=""),a.nodeType===Node.TEXT_NODE){a.textContent=b;return}a.innerHTML=b,goLive.connectElement(a)}function handleDiffAppend(c,a){const{content:d}=c,b=document.createElement("div");b.innerHTML=d;const e=b.firstChild;a.appendChild(e),goLive.connectElement(a)}function handleDiffMove(c,a){const b=a.parentNode;b.removeChild(a),b.insertBefore(a,getElementChild(b,c.index))}function handleDiffInsert(b,a){const{content:d}=b,c=document.createElement("div");c.innerHTML=d,a.insertBefore(c.firstChild,getElementChild(a,b.index)),goLive.connectElement(a)}const getComponentIdFromElement=a=>{const b=a.getAttribute("go-live-component-id");return b?b:a.parentElement?getComponentIdFromElement(a.parentElement):void 0}
   </script>
 </html>
 `
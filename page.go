@@ -2,8 +2,10 @@ package golive
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"html/template"
+	"sync"
 )
 
 var BasePage *template.Template
@@ -19,9 +21,16 @@ func init() {
 type PageEnum struct {
 	EventLiveInput          string
 	EventLiveMethod         string
+	EventLiveSubmit         string
+	EventLiveUpload         string
+	EventLiveUploadChunk    string
+	EventLiveNavigate       string
+	EventLiveQuery          string
+	EventLiveBatch          string
 	EventLiveDom            string
 	EventLiveConnectElement string
 	EventLiveError          string
+	SSEHeader               string
 	DiffSetAttr             DiffType
 	DiffRemoveAttr          DiffType
 	DiffReplace             DiffType
@@ -29,12 +38,16 @@ type PageEnum struct {
 	DiffSetInnerHTML        DiffType
 	DiffAppend              DiffType
 	DiffMove                DiffType
+	DiffInsert              DiffType
 }
 
 type LivePageEvent struct {
 	Type      int
 	Component *LiveComponent
 	Source    *EventSource
+
+	// Run is the function of PageRun events
+	Run func()
 }
 
 type LiveEventsChannel chan LivePageEvent
@@ -45,9 +58,31 @@ type Page struct {
 	ComponentsLifeCycle *ComponentLifeCycle
 
 	entryComponent *LiveComponent
+	entryMu        sync.RWMutex
 
 	// Components is a list that handle all the components from the page
 	Components map[string]*LiveComponent
+
+	events    pageEvents
+	startOnce sync.Once
+
+	// connected are the components the browser was told about through
+	// EventLiveConnectElement
+	connected   map[string]bool
+	connectedMu sync.Mutex
+
+	ctx       context.Context
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// pageEvents keeps the events of the page in order until the session
+// reads them. Posting never blocks, so components notify the page while
+// the session renders them.
+type pageEvents struct {
+	mu      sync.Mutex
+	pending []LivePageEvent
+	wake    chan struct{}
 }
 
 type PageContent struct {
@@ -69,18 +104,49 @@ func NewLivePage(c *LiveComponent) *Page {
 		Events:              pageEventsChannel,
 		ComponentsLifeCycle: &componentsUpdatesChannel,
 		Components:          make(map[string]*LiveComponent),
+		events:              pageEvents{wake: make(chan struct{}, 1)},
+		done:                make(chan struct{}),
 	}
 }
 
+// Context returns the page context, the context of the session of the
+// page.
+func (lp *Page) Context() context.Context {
+	if lp.ctx == nil {
+		return context.Background()
+	}
+
+	return lp.ctx
+}
+
+// Close stops the goroutines of the page, after its entry component was
+// killed.
+func (lp *Page) Close() {
+	lp.closeOnce.Do(func() {
+		close(lp.done)
+	})
+}
+
 func (lp *Page) SetContent(c PageContent) {
 	lp.content = c
 }
 
+// start runs the goroutines delivering the events of the page.
+func (lp *Page) start() {
+	lp.startOnce.Do(func() {
+		// Enable components lifecycle channel receiver
+		lp.enableComponentLifeCycleReceiver()
+
+		go lp.forwardEvents()
+	})
+}
+
 // Call the Component in sequence of life cycle
 func (lp *Page) Mount() {
+	lp.start()
 
-	// Enable components lifecycle channel receiver
-	lp.enableComponentLifeCycleReceiver()
+	lp.entryComponent.navigate = lp.Navigate
+	lp.entryComponent.schedule = lp.Run
 
 	// pass mount live Component with lifecycle channel
 	err := lp.entryComponent.Create(lp.ComponentsLifeCycle)
@@ -109,9 +175,16 @@ func (lp *Page) Render() (string, error) {
 	lp.content.Enum = PageEnum{
 		EventLiveInput:          EventLiveInput,
 		EventLiveMethod:         EventLiveMethod,
+		EventLiveSubmit:         EventLiveSubmit,
+		EventLiveUpload:         EventLiveUpload,
+		EventLiveUploadChunk:    EventLiveUploadChunk,
+		EventLiveNavigate:       EventLiveNavigate,
+		EventLiveQuery:          EventLiveQuery,
+		EventLiveBatch:          EventLiveBatch,
 		EventLiveDom:            EventLiveDom,
 		EventLiveError:          EventLiveError,
 		EventLiveConnectElement: EventLiveConnectElement,
+		SSEHeader:               SSEHeader,
 		DiffSetAttr:             SetAttr,
 		DiffRemoveAttr:          RemoveAttr,
 		DiffReplace:             Replace,
@@ -119,6 +192,7 @@ func (lp *Page) Render() (string, error) {
 		DiffSetInnerHTML:        SetInnerHTML,
 		DiffAppend:              Append,
 		DiffMove:                Move,
+		DiffInsert:              Insert,
 	}
 	lp.content.EnumLiveError = LiveErrorMap()
 
@@ -127,25 +201,203 @@ func (lp *Page) Render() (string, error) {
 	return writer.String(), err
 }
 
+// entry returns the entry component, which changes on navigation.
+func (lp *Page) entry() *LiveComponent {
+	lp.entryMu.RLock()
+	defer lp.entryMu.RUnlock()
+
+	return lp.entryComponent
+}
+
+// Navigate asks the page to swap its entry component by the component of
+// the route of url. The swap happens in the goroutine of the page events.
+func (lp *Page) Navigate(url string) {
+	lp.EmitWithSource(PageNavigate, lp.entry(), &EventSource{
+		Type:  EventSourceNavigate,
+		Value: url,
+	})
+}
+
+// swapEntryComponent creates and mounts c as the new entry component,
+// killing the previous one. It returns the render of c.
+func (lp *Page) swapEntryComponent(c *LiveComponent) (string, error) {
+	c.navigate = lp.Navigate
+	c.schedule = lp.Run
+
+	// The component failing to be created or mounted, even by a panic, is
+	// killed, its tickers and subscriptions do not outlive it
+	swapped := false
+	defer func() {
+		if !swapped && c.IsCreated && !c.Exited {
+			if err := c.Kill(); err != nil {
+				c.log(LogError, "swap entry component: kill component", logEx{"name": c.Name, "error": err})
+			}
+		}
+	}()
+
+	if err := c.Create(lp.ComponentsLifeCycle); err != nil {
+		return "", fmt.Errorf("create entry component: %w", err)
+	}
+
+	if err := c.Mount(); err != nil {
+		return "", fmt.Errorf("mount entry component: %w", err)
+	}
+
+	rendered, err := c.Render()
+
+	if err != nil {
+		return "", fmt.Errorf("render entry component: %w", err)
+	}
+
+	swapped = true
+
+	lp.entryMu.Lock()
+	previous := lp.entryComponent
+	lp.entryComponent = c
+	lp.entryMu.Unlock()
+
+	if !previous.Exited {
+		if err := previous.Kill(); err != nil {
+			return "", fmt.Errorf("kill entry component: %w", err)
+		}
+	}
+
+	return rendered, nil
+}
+
+// renderEntryPatch renders the whole entry component, returning a patch
+// that replaces it in the browser.
+func (lp *Page) renderEntryPatch() (*PatchBrowser, error) {
+	entry := lp.entry()
+
+	if entry.component == nil {
+		return nil, ErrComponentNil
+	}
+
+	_, root, err := entry.renderer.Render(entry.component)
+
+	if err != nil {
+		return nil, err
+	}
+
+	element := root.FirstChild
+	for element != nil && !nodeIsElement(element) {
+		element = element.NextSibling
+	}
+
+	if element == nil {
+		return nil, fmt.Errorf("entry component without root element")
+	}
+
+	selector, err := selectorFromNode(element)
+
+	if err != nil {
+		return nil, fmt.Errorf("selector from node: %w", err)
+	}
+
+	content, err := renderNodeToString(element)
+
+	if err != nil {
+		return nil, err
+	}
+
+	patch := NewPatchBrowser(entry.Name)
+	patch.Type = EventLiveDom
+	patch.AddInstruction(PatchInstruction{
+		Name:     EventLiveDom,
+		Type:     Replace.toString(),
+		Content:  content,
+		Selector: selector.toString(),
+	})
+
+	return patch, nil
+}
+
 func (lp *Page) Emit(lts int, c *LiveComponent) {
 	lp.EmitWithSource(lts, c, nil)
 }
 
+// EmitWithSource posts the event to the page, it is delivered to Events
+// after the events posted before it.
 func (lp *Page) EmitWithSource(lts int, c *LiveComponent, source *EventSource) {
 	if c == nil {
-		c = lp.entryComponent
+		c = lp.entry()
 	}
 
-	lp.Events <- LivePageEvent{
+	lp.post(LivePageEvent{
 		Type:      lts,
 		Component: c,
 		Source:    source,
+	})
+}
+
+// Run runs fn in the goroutine of the page events, where the session
+// renders the components, after the events posted before it.
+func (lp *Page) Run(fn func()) {
+	lp.post(LivePageEvent{
+		Type:      PageRun,
+		Component: lp.entry(),
+		Run:       fn,
+	})
+}
+
+func (lp *Page) post(evt LivePageEvent) {
+	lp.events.mu.Lock()
+	lp.events.pending = append(lp.events.pending, evt)
+	lp.events.mu.Unlock()
+
+	select {
+	case lp.events.wake <- struct{}{}:
+	default:
+	}
+}
+
+// forwardEvents delivers the posted events to Events, in order, until the
+// page is closed.
+func (lp *Page) forwardEvents() {
+	for {
+		lp.events.mu.Lock()
+		pending := lp.events.pending
+		lp.events.pending = nil
+		lp.events.mu.Unlock()
+
+		for _, evt := range pending {
+			select {
+			case lp.Events <- evt:
+			case <-lp.done:
+				return
+			}
+		}
+
+		select {
+		case <-lp.events.wake:
+		case <-lp.done:
+			return
+		}
+	}
+}
+
+func (lp *Page) markConnected(name string) {
+	lp.connectedMu.Lock()
+	defer lp.connectedMu.Unlock()
+
+	if lp.connected == nil {
+		lp.connected = map[string]bool{}
 	}
+
+	lp.connected[name] = true
+}
+
+func (lp *Page) isConnected(name string) bool {
+	lp.connectedMu.Lock()
+	defer lp.connectedMu.Unlock()
+
+	return lp.connected[name]
 }
 
 func (lp *Page) HandleBrowserEvent(m BrowserEvent) error {
 
-	c := lp.entryComponent.findComponentByID(m.ComponentID)
+	c := lp.entry().findComponentByID(m.ComponentID)
 
 	if c == nil {
 		return fmt.Errorf("Component not found with id: %s", m.ComponentID)
@@ -156,11 +408,39 @@ func (lp *Page) HandleBrowserEvent(m BrowserEvent) error {
 	var err error
 	switch m.Name {
 	case EventLiveInput:
+		if !c.IsPathExposed(m.StateKey) {
+			return c.rejectBrowserEvent("path", m.StateKey)
+		}
+
 		err = c.SetValueInPath(m.StateValue, m.StateKey)
 		source = &EventSource{Type: EventSourceInput, Value: m.StateKey}
 	case EventLiveMethod:
+		if !c.IsMethodExposed(m.MethodName) {
+			return c.rejectBrowserEvent("method", m.MethodName)
+		}
+
 		err = c.InvokeMethodInPath(m.MethodName, m.MethodData, m.DOMEvent)
+	case EventLiveSubmit:
+		if !c.IsMethodExposed(m.MethodName) {
+			return c.rejectBrowserEvent("method", m.MethodName)
+		}
+
+		err = c.InvokeMethodWithForm(m.MethodName, m.FormData, m.MethodData)
+	case EventLiveUpload:
+		if !c.IsMethodExposed(m.MethodName) {
+			return c.rejectBrowserEvent("method", m.MethodName)
+		}
+
+		err = c.startUpload(m.MethodName, m.MethodData, m.Upload)
+	case EventLiveUploadChunk:
+		err = c.receiveUploadChunk(m.Upload)
 	case EventLiveDisconnect:
+		// The browser only disconnects the children it was told about, the
+		// entry component lives as long as the page
+		if c == lp.entry() || !lp.isConnected(c.Name) {
+			return c.rejectBrowserEvent("disconnect", c.Name)
+		}
+
 		err = c.Kill()
 	}
 
@@ -171,13 +451,26 @@ func (lp *Page) HandleBrowserEvent(m BrowserEvent) error {
 
 const PageComponentUpdated = 1
 const PageComponentMounted = 2
+const PageNavigate = 3
+const PageResync = 4
+const PageClose = 5
+const PageRun = 6
 
 func (lp *Page) enableComponentLifeCycleReceiver() {
 
 	go func() {
 		for {
-			ls := <-*lp.ComponentsLifeCycle
+			var ls ComponentLifeTimeMessage
+
+			select {
+			case ls = <-*lp.ComponentsLifeCycle:
+			case <-lp.done:
+				return
+			}
 
+			// Emitting does not wait for the session, a session rendering a
+			// component may create children, which notifies this receiver
+			// while the session is not reading events
 			switch ls.Stage {
 			case Created:
 				lp.Emit(PageComponentMounted, ls.Component)
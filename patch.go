@@ -21,6 +21,8 @@ type PatchBrowser struct {
 	Type         string             `json:"t"`
 	Message      string             `json:"m"`
 	Instructions []PatchInstruction `json:"i,omitempty"`
+	// Patches are the patches of a EventLiveBatch
+	Patches []PatchBrowser `json:"p,omitempty"`
 }
 
 func NewPatchBrowser(componentID string) *PatchBrowser {
@@ -0,0 +1,180 @@
+package golive
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrComponentWithoutPubSub = errors.New("Component without pubsub defined")
+	ErrSubscriberPanic        = errors.New("subscriber panicked")
+)
+
+// PubSubBackend delivers the payloads published to a topic to the
+// subscribers of the topic. MemoryPubSub delivers them in the process, a
+// backend connecting the nodes of a cluster can implement it to reach the
+// sessions of every node, encoding the payloads as needed.
+type PubSubBackend interface {
+	Publish(topic string, payload interface{}) error
+	// Subscribe registers the handler of the topic, returning the function
+	// removing it.
+	Subscribe(topic string, handler func(payload interface{})) (func(), error)
+}
+
+// MemoryPubSub is the in-process PubSubBackend. Handlers are called in
+// the goroutine publishing, in the order they subscribed. A handler
+// panicking does not stop the delivery to the others, Publish returns
+// ErrSubscriberPanic instead.
+type MemoryPubSub struct {
+	mu     sync.RWMutex
+	topics map[string][]*memorySubscription
+}
+
+type memorySubscription struct {
+	handler func(payload interface{})
+}
+
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{
+		topics: make(map[string][]*memorySubscription),
+	}
+}
+
+func (ps *MemoryPubSub) Publish(topic string, payload interface{}) error {
+	ps.mu.RLock()
+	subscriptions := ps.topics[topic]
+	ps.mu.RUnlock()
+
+	// The slice is replaced on changes, handlers can subscribe and
+	// unsubscribe while it is delivered
+	var err error
+	for _, s := range subscriptions {
+		if panicErr := s.deliver(payload); panicErr != nil && err == nil {
+			err = panicErr
+		}
+	}
+
+	return err
+}
+
+func (s *memorySubscription) deliver(payload interface{}) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v", ErrSubscriberPanic, p)
+		}
+	}()
+
+	s.handler(payload)
+
+	return nil
+}
+
+func (ps *MemoryPubSub) Subscribe(topic string, handler func(payload interface{})) (func(), error) {
+	s := &memorySubscription{handler: handler}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	subscriptions := ps.topics[topic]
+	ps.topics[topic] = append(subscriptions[:len(subscriptions):len(subscriptions)], s)
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			ps.unsubscribe(topic, s)
+		})
+	}
+
+	return unsubscribe, nil
+}
+
+func (ps *MemoryPubSub) unsubscribe(topic string, s *memorySubscription) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	subscriptions := make([]*memorySubscription, 0, len(ps.topics[topic]))
+	for _, subscription := range ps.topics[topic] {
+		if subscription != s {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+
+	if len(subscriptions) == 0 {
+		delete(ps.topics, topic)
+		return
+	}
+
+	ps.topics[topic] = subscriptions
+}
+
+// Subscribers returns how many handlers the topic has.
+func (ps *MemoryPubSub) Subscribers(topic string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return len(ps.topics[topic])
+}
+
+type componentSubscriptions struct {
+	mu           sync.Mutex
+	unsubscribes []func()
+}
+
+// Subscribe calls the handler with the payloads published to the topic,
+// by any session, updating the component after each. The handler runs in
+// the goroutine of the page events of the component, publishers do not
+// wait for it. The subscription ends when the component is killed.
+func (l *LiveComponent) Subscribe(topic string, handler func(payload interface{})) error {
+	if l.pubsub == nil {
+		return ErrComponentWithoutPubSub
+	}
+
+	unsubscribe, err := l.pubsub.Subscribe(topic, func(payload interface{}) {
+		l.dispatch(func() {
+			if l.Ctx().Err() != nil {
+				return
+			}
+
+			handler(payload)
+			l.Update()
+		})
+	})
+
+	if err != nil {
+		return err
+	}
+
+	l.subscriptions.mu.Lock()
+	l.subscriptions.unsubscribes = append(l.subscriptions.unsubscribes, unsubscribe)
+	l.subscriptions.mu.Unlock()
+
+	return nil
+}
+
+// Publish sends the payload to the subscribers of the topic.
+func (l *LiveComponent) Publish(topic string, payload interface{}) error {
+	if l.pubsub == nil {
+		return ErrComponentWithoutPubSub
+	}
+
+	return l.pubsub.Publish(topic, payload)
+}
+
+// unsubscribeAll ends the subscriptions of the component.
+func (l *LiveComponent) unsubscribeAll() {
+	l.subscriptions.mu.Lock()
+	unsubscribes := l.subscriptions.unsubscribes
+	l.subscriptions.unsubscribes = nil
+	l.subscriptions.mu.Unlock()
+
+	for _, unsubscribe := range unsubscribes {
+		unsubscribe()
+	}
+}
+
+// Publish sends the payload to the subscribers of the topic in every
+// session.
+func (s *LiveServer) Publish(topic string, payload interface{}) error {
+	return s.PubSub.Publish(topic, payload)
+}
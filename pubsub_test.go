@@ -0,0 +1,183 @@
+package golive
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type chatRoom struct {
+	LiveComponentWrapper
+	Messages []string
+}
+
+func (c *chatRoom) Mounted(l *LiveComponent) {
+	_ = l.Subscribe("chat", func(payload interface{}) {
+		c.Messages = append(c.Messages, payload.(string))
+	})
+}
+
+func (c *chatRoom) TemplateHandler(_ *LiveComponent) string {
+	return `<ul>{{ range .Messages }}<li>{{ . }}</li>{{ end }}</ul>`
+}
+
+func TestMemoryPubSub_Unsubscribe(t *testing.T) {
+	ps := NewMemoryPubSub()
+
+	received := 0
+	unsubscribe, _ := ps.Subscribe("topic", func(_ interface{}) { received++ })
+
+	// Unsubscribing while delivering must not skip other handlers
+	var unsubscribeSelf func()
+	unsubscribeSelf, _ = ps.Subscribe("topic", func(_ interface{}) { unsubscribeSelf() })
+	_, _ = ps.Subscribe("topic", func(_ interface{}) { received++ })
+
+	_ = ps.Publish("topic", nil)
+
+	if received != 2 || ps.Subscribers("topic") != 2 {
+		t.Fatal("unexpected delivery", received, ps.Subscribers("topic"))
+	}
+
+	unsubscribe()
+	unsubscribe()
+
+	_ = ps.Publish("topic", nil)
+
+	if received != 3 || ps.Subscribers("topic") != 1 {
+		t.Error("unexpected delivery after unsubscribe", received, ps.Subscribers("topic"))
+	}
+}
+
+func TestComponent_PubSubAcrossComponents(t *testing.T) {
+	s := NewServer()
+
+	rooms := make([]*chatRoom, 2)
+	components := make([]*LiveComponent, 2)
+
+	for i := range rooms {
+		rooms[i] = &chatRoom{}
+		components[i] = NewLiveComponent("Chat", rooms[i])
+		components[i].log = s.Log
+		components[i].pubsub = s.PubSub
+
+		if err := components[i].Create(nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := components[i].Mount(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Publish("chat", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, room := range rooms {
+		if len(room.Messages) != 1 || room.Messages[0] != "hello" {
+			t.Errorf("room %d did not receive the message: %v", i, room.Messages)
+		}
+	}
+
+	if err := components[0].Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := components[1].Publish("chat", "bye"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := s.PubSub.(*MemoryPubSub).Subscribers("chat"); n != 1 {
+		t.Error("killed component still subscribed, subscribers", n)
+	}
+
+	if len(rooms[0].Messages) != 1 || len(rooms[1].Messages) != 2 {
+		t.Error("unexpected messages", rooms[0].Messages, rooms[1].Messages)
+	}
+}
+
+func TestComponent_PubSubWithoutBackend(t *testing.T) {
+	c := NewLiveComponent("Chat", &chatRoom{})
+
+	if err := c.Subscribe("chat", func(_ interface{}) {}); !errors.Is(err, ErrComponentWithoutPubSub) {
+		t.Error("expecting without pubsub error, received", err)
+	}
+}
+
+type slowRoom struct {
+	LiveComponentWrapper
+	release  chan struct{}
+	received chan string
+}
+
+func (r *slowRoom) Mounted(l *LiveComponent) {
+	_ = l.Subscribe("chat", func(payload interface{}) {
+		if payload == "panic" {
+			panic("subscriber")
+		}
+
+		<-r.release
+		r.received <- payload.(string)
+	})
+}
+
+func (r *slowRoom) TemplateHandler(_ *LiveComponent) string {
+	return `<p>room</p>`
+}
+
+func TestComponent_PubSubThroughPage(t *testing.T) {
+	s := NewServer()
+	s.Log = func(int, string, map[string]interface{}) {}
+
+	room := &slowRoom{release: make(chan struct{}), received: make(chan string, 1)}
+	lc := NewLiveComponent("Room", room)
+	lc.log = s.Log
+
+	if _, err := s.HandleFirstRequest(lc, PageContent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	published := make(chan error, 1)
+	go func() {
+		_ = s.Publish("chat", "panic")
+		published <- s.Publish("chat", "hello")
+	}()
+
+	// The publisher does not wait for the slow subscriber
+	select {
+	case err := <-published:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("publisher blocked by the subscriber")
+	}
+
+	close(room.release)
+
+	// The page survives the panicking subscriber
+	select {
+	case msg := <-room.received:
+		if msg != "hello" {
+			t.Error("unexpected message", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not delivered")
+	}
+}
+
+func TestMemoryPubSub_PanickingSubscriber(t *testing.T) {
+	ps := NewMemoryPubSub()
+
+	received := 0
+	_, _ = ps.Subscribe("topic", func(_ interface{}) { panic("subscriber") })
+	_, _ = ps.Subscribe("topic", func(_ interface{}) { received++ })
+
+	if err := ps.Publish("topic", nil); !errors.Is(err, ErrSubscriberPanic) {
+		t.Error("expecting subscriber panic error, received", err)
+	}
+
+	if received != 1 {
+		t.Error("delivery stopped by the panicking subscriber")
+	}
+}
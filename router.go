@@ -0,0 +1,47 @@
+package golive
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrRouteNotFound = errors.New("route not found")
+
+// LiveRouter maps paths to the entry components of pages, so a session
+// can navigate between them without loading a new page. Navigations run
+// on the connection of the session, the HTTP middlewares of the routes
+// do not run for them, the components get the page context of the first
+// request instead.
+type LiveRouter struct {
+	mu     sync.RWMutex
+	routes map[string]func() *LiveComponent
+}
+
+func NewLiveRouter() *LiveRouter {
+	return &LiveRouter{
+		routes: make(map[string]func() *LiveComponent),
+	}
+}
+
+// Handle registers the function creating the entry component of path.
+// Paths are matched exactly.
+func (r *LiveRouter) Handle(path string, f func() *LiveComponent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.routes[path] = f
+}
+
+// Match creates the entry component of the route of the path.
+func (r *LiveRouter) Match(path string) (*LiveComponent, error) {
+	r.mu.RLock()
+	f, ok := r.routes[path]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, ErrRouteNotFound)
+	}
+
+	return f(), nil
+}
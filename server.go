@@ -2,7 +2,11 @@ package golive
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +20,42 @@ type LiveServer struct {
 	// CookieName ...
 	CookieName string
 	Log        Log
+
+	// ResumeGracePeriod is how long a session is kept after its connection
+	// drops, so the browser can reconnect without losing the page state.
+	ResumeGracePeriod time.Duration
+
+	// Router has the routes the sessions can navigate to through
+	// go-live-link and Navigate.
+	Router *LiveRouter
+
+	// PubSub delivers the payloads published by components to the
+	// subscribed components of every session.
+	PubSub PubSubBackend
+
+	// FrameInterval is how long the sessions collect the updates of their
+	// components before rendering them together.
+	FrameInterval time.Duration
+
+	// QueueSize is how many messages each session keeps for its
+	// connection, and QueuePolicy what it does when they are more.
+	QueueSize   int
+	QueuePolicy QueuePolicy
+
+	// EventRate is how many events per second the browser can send to its
+	// session, in bursts of up to EventBurst. The events above it are
+	// dropped. Zero disables the limit.
+	EventRate  float64
+	EventBurst int
+
+	// PingInterval is how often websocket connections are pinged. The
+	// connections not answering for two intervals are closed, so half open
+	// connections release their sessions. Zero disables the pings.
+	PingInterval time.Duration
+
+	mu           sync.Mutex
+	shuttingDown bool
+	connections  sync.WaitGroup
 }
 
 type LiveResponse struct {
@@ -26,15 +66,44 @@ type LiveResponse struct {
 func NewServer() *LiveServer {
 	logger := NewLoggerBasic()
 	return &LiveServer{
-		Wire:       NewWire(),
-		CookieName: "_csrf_token",
-		Log:        logger.Log,
+		Wire:              NewWire(),
+		CookieName:        "_csrf_token",
+		Log:               logger.Log,
+		ResumeGracePeriod: 30 * time.Second,
+		Router:            NewLiveRouter(),
+		PubSub:            NewMemoryPubSub(),
+		FrameInterval:     DefaultFrameInterval,
+		QueueSize:         DefaultQueueSize,
+		QueuePolicy:       QueueDropResync,
+		EventRate:         DefaultEventRate,
+		EventBurst:        DefaultEventBurst,
+		PingInterval:      DefaultPingInterval,
 	}
 }
 
 func (s *LiveServer) HandleFirstRequest(lc *LiveComponent, c PageContent) (*LiveResponse, error) {
+	return s.HandleFirstRequestWithQuery(lc, c, nil)
+}
+
+// HandleFirstRequestWithQuery is HandleFirstRequest for a request with a
+// query, setting the fields of the components bound to its parameters.
+func (s *LiveServer) HandleFirstRequestWithQuery(lc *LiveComponent, c PageContent, query url.Values) (*LiveResponse, error) {
+	return s.HandleFirstRequestWithContext(context.Background(), lc, c, query)
+}
+
+// HandleFirstRequestWithContext is HandleFirstRequestWithQuery keeping
+// pageCtx as the page context of the session. Its values are available to
+// the components through their Ctx, its cancellation is ignored.
+func (s *LiveServer) HandleFirstRequestWithContext(pageCtx context.Context, lc *LiveComponent, c PageContent, query url.Values) (*LiveResponse, error) {
 	/* Create session to the new user */
-	sessionKey, session, err := s.Wire.CreateSession()
+	sessionKey, session, err := s.createSession()
+	if errors.Is(err, ErrServerShutdown) {
+		return &LiveResponse{
+			Rendered: "<h1> Page with error </h1>",
+			Session:  "",
+		}, err
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +111,16 @@ func (s *LiveServer) HandleFirstRequest(lc *LiveComponent, c PageContent) (*Live
 	s.Log(LogInfo, "http request", logEx{"Component": lc.Name, "session": sessionKey})
 
 	session.log = s.Log
+	session.router = s.Router
+	session.pubsub = s.PubSub
+	session.FrameInterval = s.FrameInterval
+	session.setQueue(s.QueueSize, s.QueuePolicy)
+	session.setContext(pageCtx)
+	session.setEventLimit(s.EventRate, s.EventBurst)
+
+	lc.pubsub = s.PubSub
+	lc.ctx = session.ctx
+	lc.bindQuery(query)
 
 	// Instantiate a page to attach to a session
 	p := NewLivePage(lc)
@@ -54,11 +133,8 @@ func (s *LiveServer) HandleFirstRequest(lc *LiveComponent, c PageContent) (*Live
 	// that will be needed in mount
 	session.ActivatePage(p)
 
-	// Mount page
-	p.Mount()
-
-	// Render page
-	rendered, err := p.Render()
+	// Mount and render page
+	rendered, err := session.firstRender(p)
 
 	if err != nil {
 		return &LiveResponse{
@@ -71,8 +147,19 @@ func (s *LiveServer) HandleFirstRequest(lc *LiveComponent, c PageContent) (*Live
 }
 
 func (s *LiveServer) HandleHTMLRequest(ctx *fiber.Ctx, lc *LiveComponent, c PageContent) {
+	s.HandleHTMLRequestWithContext(ctx, context.Background(), lc, c)
+}
+
+// HandleHTMLRequestWithContext is HandleHTMLRequest keeping pageCtx as the
+// page context of the session.
+func (s *LiveServer) HandleHTMLRequestWithContext(ctx *fiber.Ctx, pageCtx context.Context, lc *LiveComponent, c PageContent) {
+
+	query, err := url.ParseQuery(string(ctx.Context().QueryArgs().QueryString()))
+	if err != nil {
+		s.Log(LogWarn, "handle html request: parse query", logEx{"error": err})
+	}
 
-	lr, err := s.HandleFirstRequest(lc, c)
+	lr, err := s.HandleFirstRequestWithContext(pageCtx, lc, c, query)
 
 	if lr == nil {
 		s.Log(LogPanic, "no live page", logEx{"error": err})
@@ -86,9 +173,10 @@ func (s *LiveServer) HandleHTMLRequest(ctx *fiber.Ctx, lc *LiveComponent, c Page
 	}
 
 	ctx.Cookie(&fiber.Cookie{
-		Name:    s.CookieName,
-		Value:   lr.Session,
-		Expires: time.Now().Add(24 * time.Hour),
+		Name:     s.CookieName,
+		Value:    lr.Session,
+		Expires:  time.Now().Add(24 * time.Hour),
+		SameSite: "Lax",
 	})
 
 	ctx.Response().Header.SetContentType("text/html")
@@ -105,6 +193,14 @@ func (s *LiveServer) CreateHTMLHandler(f func() *LiveComponent, c PageContent) f
 	}
 }
 
+// CreateRouteHandler registers the component in the Router, so live pages
+// can navigate to the path, and returns the handler of its first request.
+func (s *LiveServer) CreateRouteHandler(path string, f func() *LiveComponent, c PageContent) func(ctx *fiber.Ctx) error {
+	s.Router.Handle(path, f)
+
+	return s.CreateHTMLHandler(f, c)
+}
+
 // HTTPMiddleware Middleware to run on HTTP requests.
 type HTTPMiddleware func(next HTTPHandlerCtx) HTTPHandlerCtx
 
@@ -134,76 +230,171 @@ func (s *LiveServer) CreateHTMLHandlerWithMiddleware(f func(ctx context.Context)
 		lc := f(ctx)
 		lc.log = s.Log
 
-		s.HandleHTMLRequest(c, lc, content)
+		s.HandleHTMLRequestWithContext(c, ctx, lc, content)
 
 		return nil
 	}
 }
 
+// HandleWSRequest handles a websocket connection opened by a page served
+// through the Fiber handlers.
 func (s *LiveServer) HandleWSRequest(c *websocket.Conn) {
+	c.EnableWriteCompression(true)
+
+	s.HandleConnection(c.Cookies(s.CookieName), c)
+}
+
+// LiveConnection is the bidirectional channel between a session and the
+// browser. Any transport able to exchange JSON messages can carry a session.
+type LiveConnection interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	Close() error
+	SetCloseHandler(h func(code int, text string) error)
+}
+
+// HandleConnection attaches the connection to the session created on the
+// first request, or resumes a closed one, and runs the message loop until
+// the connection is closed.
+func (s *LiveServer) HandleConnection(sessionKey string, c LiveConnection) {
+	s.handleConnection(&ConnectRequest{SessionKey: sessionKey}, c, nil)
+}
+
+// handleConnection is HandleConnection validating the connection with
+// connect, when given.
+func (s *LiveServer) handleConnection(r *ConnectRequest, c LiveConnection, connect ConnectHandler) {
+	sessionKey := r.SessionKey
+
 	defer func() {
 		payload := recover()
 		if payload != nil {
-			s.Log(LogWarn, fmt.Sprintf("ws request panic recovered: %v", payload), nil)
+			s.Log(LogWarn, fmt.Sprintf("connection panic recovered: %v", payload), nil)
 		}
 	}()
 
-	c.EnableWriteCompression(true)
+	if !s.acceptConnection() {
+		s.refuseConnection(c, LiveErrorServerShutdown)
+		return
+	}
 
-	sessionKey := c.Cookies(s.CookieName)
+	defer s.connections.Done()
 
-	s.Log(LogInfo, "websocket open", logEx{"session": sessionKey})
+	s.Log(LogInfo, "connection open", logEx{"session": sessionKey})
 
 	session := s.Wire.GetSession(sessionKey)
 
-	if session == nil || session.Status != SessionNew {
+	if session != nil && connect != nil {
+		r.Context = session.Context()
+
+		if err := connect(r); err != nil {
+			s.Log(LogWarn, "connection refused", logEx{"session": sessionKey, "error": err})
+			s.refuseConnection(c, LiveErrorConnectRefused)
+			return
+		}
+	}
+
+	exit := make(chan struct{})
+	exited := make(chan struct{})
+	var exitOnce, closeOnce sync.Once
+
+	closeSession := func() {
+		exitOnce.Do(func() {
+			close(exit)
+		})
+	}
+
+	closeConnection := func() {
+		closeOnce.Do(func() {
+			if err := c.Close(); err != nil {
+				s.Log(LogError, "close connection", logEx{"error": err})
+			}
+		})
+	}
+
+	conn := &sessionConnection{
+		// Closing the connection releases a writer blocked on it
+		close: func() {
+			closeSession()
+			closeConnection()
+		},
+		closed: exited,
+	}
+
+	var resumed, ok bool
+	if session != nil {
+		resumed, ok = session.open(conn)
+
+		for !ok && session.takeOver() {
+			s.Log(LogInfo, "session taken over", logEx{"session": sessionKey})
+			resumed, ok = session.open(conn)
+		}
+	}
+
+	if !ok {
 		s.Log(LogWarn, "session not found", logEx{"session": sessionKey})
 
 		var msg PatchBrowser
 		msg.Type = EventLiveError
 		msg.Message = LiveErrorSessionNotFound
 		if err := c.WriteJSON(msg); err != nil {
-			s.Log(LogError, "handle ws request: write json", logEx{"error": err})
+			s.Log(LogError, "handle connection: write json", logEx{"error": err})
 		}
 
 		if err := c.Close(); err != nil {
-			s.Log(LogError, "close websocket connection", logEx{"error": err})
+			s.Log(LogError, "close connection", logEx{"error": err})
 		}
 
-		s.Log(LogInfo, "websocket close", logEx{"session": sessionKey})
+		s.Log(LogInfo, "connection close", logEx{"session": sessionKey})
 
 		return
 	}
 
-	session.Status = SessionOpen
-	exit := make(chan int)
-	exited := false
+	if resumed {
+		s.Log(LogInfo, "session resumed", logEx{"session": sessionKey})
+
+		session.Resume()
+	}
 
 	go func() {
+		defer close(exited)
+
+		hangup := session.hangup
+
 		for {
 			select {
 			case msg := <-session.OutChannel:
 				s.Log(LogDebug, "message out", logEx{"msg": msg, "session": sessionKey})
 
 				if err := c.WriteJSON(msg); err != nil {
-					s.Log(LogError, "handle ws request: write json", logEx{"error": err})
+					s.Log(LogError, "handle connection: write json", logEx{"error": err})
+				}
+			case <-hangup:
+				hangup = nil
+
+				// The queued messages are sent before closing
+				for _, msg := range session.drainQueue() {
+					if err := c.WriteJSON(msg); err != nil {
+						s.Log(LogError, "handle connection: write json", logEx{"error": err})
+						break
+					}
 				}
-			case <-exit:
-				exited = true
 
-				session.Status = SessionClosed
+				closeSession()
+			case <-session.queue.overflow:
+				s.Log(LogWarn, "handle connection: queue full, disconnecting", logEx{"session": sessionKey})
 
-				if err := c.Close(); err != nil {
-					s.Log(LogError, "close websocket connection", logEx{"error": err})
-				}
+				closeSession()
+			case <-exit:
+				closeConnection()
 
-				if err := session.LivePage.entryComponent.Kill(); err != nil {
-					s.Log(LogError, "handle ws request: kill page", logEx{"error": err})
-				}
+				session.close(s.ResumeGracePeriod, func() {
+					killSession(sessionKey, session)
+					s.Wire.DeleteSession(sessionKey)
 
-				s.Wire.DeleteSession(sessionKey)
+					s.Log(LogInfo, "session expired", logEx{"session": sessionKey})
+				})
 
-				s.Log(LogInfo, "websocket close", logEx{"session": sessionKey})
+				s.Log(LogInfo, "connection close", logEx{"session": sessionKey})
 
 				return
 			}
@@ -212,41 +403,108 @@ func (s *LiveServer) HandleWSRequest(c *websocket.Conn) {
 
 	c.SetCloseHandler(func(code int, text string) error {
 		// Close codes defined in RFC 6455, section 11.7.
-		s.Log(LogTrace, "ws close handler", logEx{"code": code, "text": text})
+		s.Log(LogTrace, "connection close handler", logEx{"code": code, "text": text})
 
-		exit <- 1
+		closeSession()
 		return nil
 	})
 
-	for {
-		if exited {
-			return
-		}
+	if pc, ok := c.(pingConnection); ok && s.PingInterval > 0 {
+		s.keepAlive(pc, s.PingInterval, exit)
+	}
 
+	for {
 		inMsg := BrowserEvent{}
 
 		// Loop blocks here
 		if err := c.ReadJSON(&inMsg); err != nil {
-			if websocket.IsUnexpectedCloseError(err) {
-				// This seems to happen when running in Docker
-				if !exited {
-					s.Log(LogWarn, "handle ws request: unexpected connection close", nil)
-
-					exit <- 1
-				}
+			if isMalformedMessage(err) {
+				s.Log(LogError, "handle connection: read json", logEx{"error": err})
+				continue
+			}
 
-				return
+			select {
+			case <-exit:
+			default:
+				// This seems to happen when running in Docker
+				s.Log(LogWarn, "handle connection: unexpected connection close", logEx{"error": err})
 			}
 
-			s.Log(LogError, "handle ws request: read json", logEx{"error": err})
+			closeSession()
+			<-exited
 
-			continue
+			return
 		}
 
 		s.Log(LogDebug, "message in", logEx{"msg": inMsg, "session": sessionKey})
 
-		if err := session.IngestMessage(inMsg); err != nil {
-			s.Log(LogError, "handle ws request: ingest message", logEx{"error": err})
+		if err := session.IngestMessage(inMsg); errors.Is(err, ErrRateLimited) {
+			s.Log(LogDebug, "handle connection: event dropped", logEx{"error": err, "session": sessionKey})
+		} else if err != nil {
+			s.Log(LogError, "handle connection: ingest message", logEx{"error": err})
 		}
 	}
 }
+
+// DefaultPingInterval is the default PingInterval of servers.
+const DefaultPingInterval = 15 * time.Second
+
+// pingConnection is implemented by websocket connections.
+type pingConnection interface {
+	SetReadDeadline(t time.Time) error
+	SetPongHandler(h func(appData string) error)
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+}
+
+// keepAlive pings the browser until exit is closed. Reads fail once the
+// browser does not answer for two intervals, as with half open
+// connections, ending the message loop. Browsers answer pings on their own.
+func (s *LiveServer) keepAlive(c pingConnection, interval time.Duration, exit <-chan struct{}) {
+	timeout := 2 * interval
+
+	_ = c.SetReadDeadline(time.Now().Add(timeout))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-exit:
+				return
+			case <-ticker.C:
+				if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval)); err != nil {
+					s.Log(LogDebug, "handle connection: ping", logEx{"error": err})
+					return
+				}
+			}
+		}
+	}()
+}
+
+// refuseConnection tells the browser why its connection is refused and
+// closes it.
+func (s *LiveServer) refuseConnection(c LiveConnection, reason string) {
+	var msg PatchBrowser
+	msg.Type = EventLiveError
+	msg.Message = reason
+	if err := c.WriteJSON(msg); err != nil {
+		s.Log(LogError, "handle connection: write json", logEx{"error": err})
+	}
+
+	if err := c.Close(); err != nil {
+		s.Log(LogError, "close connection", logEx{"error": err})
+	}
+}
+
+// isMalformedMessage reports if the read error was caused by the message
+// content, in which case the connection is still usable.
+func isMalformedMessage(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
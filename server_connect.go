@@ -0,0 +1,166 @@
+package golive
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	fiberws "github.com/gofiber/websocket/v2"
+)
+
+// ConnectRequest is the handshake of a connection to a session.
+type ConnectRequest struct {
+	SessionKey string
+
+	// Context is the page context of the session, with the values set by
+	// the middlewares of its first request
+	Context context.Context
+
+	header func(key string) string
+	cookie func(name string) string
+	query  func(key string) string
+}
+
+// Header returns the header of the handshake request. Fiber websocket
+// connections do not keep the headers, it is always empty for them. For
+// the event requests of Server-Sent Events it is the header of the event
+// request.
+func (r *ConnectRequest) Header(key string) string {
+	if r.header == nil {
+		return ""
+	}
+	return r.header(key)
+}
+
+// Cookie returns the value of the cookie of the handshake request.
+func (r *ConnectRequest) Cookie(name string) string {
+	if r.cookie == nil {
+		return ""
+	}
+	return r.cookie(name)
+}
+
+// Query returns the query parameter of the handshake request.
+func (r *ConnectRequest) Query(key string) string {
+	if r.query == nil {
+		return ""
+	}
+	return r.query(key)
+}
+
+// ConnectHandler validates the connection of a browser to its session.
+// Returning an error refuses the connection.
+type ConnectHandler func(r *ConnectRequest) error
+
+// ConnectMiddleware Middleware to run on the handshake of connections,
+// as HTTPMiddleware runs on the first request of the page.
+type ConnectMiddleware func(next ConnectHandler) ConnectHandler
+
+// connectChain builds the handler running the middlewares in order.
+func connectChain(middlewares []ConnectMiddleware) ConnectHandler {
+	if len(middlewares) == 0 {
+		return nil
+	}
+
+	h := ConnectHandler(func(_ *ConnectRequest) error {
+		return nil
+	})
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
+
+// CreateWSHandlerWithMiddleware is HandleWSRequest running the
+// middlewares before attaching the connection to its session.
+func (s *LiveServer) CreateWSHandlerWithMiddleware(middlewares ...ConnectMiddleware) func(c *fiberws.Conn) {
+	connect := connectChain(middlewares)
+
+	return func(c *fiberws.Conn) {
+		c.EnableWriteCompression(true)
+
+		s.handleConnection(&ConnectRequest{
+			SessionKey: c.Cookies(s.CookieName),
+			cookie: func(name string) string {
+				return c.Cookies(name)
+			},
+			query: func(key string) string {
+				return c.Query(key)
+			},
+		}, c, connect)
+	}
+}
+
+// CreateWSHTTPHandlerWithMiddleware net/http version of
+// CreateWSHandlerWithMiddleware.
+func (s *LiveServer) CreateWSHTTPHandlerWithMiddleware(middlewares ...ConnectMiddleware) http.Handler {
+	connect := connectChain(middlewares)
+
+	upgrader := websocket.Upgrader{
+		EnableCompression: true,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sessionKey string
+		if cookie, err := r.Cookie(s.CookieName); err == nil {
+			sessionKey = cookie.Value
+		}
+
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			s.Log(LogError, "handle ws http request: upgrade", logEx{"error": err})
+			return
+		}
+
+		c.EnableWriteCompression(true)
+
+		s.handleConnection(httpConnectRequest(r, sessionKey), c, connect)
+	})
+}
+
+// httpConnectRequest is the ConnectRequest of a net/http request.
+func httpConnectRequest(r *http.Request, sessionKey string) *ConnectRequest {
+	return &ConnectRequest{
+		SessionKey: sessionKey,
+		header:     r.Header.Get,
+		cookie: func(name string) string {
+			if cookie, err := r.Cookie(name); err == nil {
+				return cookie.Value
+			}
+			return ""
+		},
+		query: r.URL.Query().Get,
+	}
+}
+
+// fiberConnectRequest is the ConnectRequest of a Fiber request. Its values
+// are copied, the event streams outlive the Fiber context.
+func fiberConnectRequest(ctx *fiber.Ctx, sessionKey string) *ConnectRequest {
+	header := http.Header{}
+	ctx.Request().Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	cookies := map[string]string{}
+	ctx.Request().Header.VisitAllCookie(func(key, value []byte) {
+		cookies[string(key)] = string(value)
+	})
+
+	query := url.Values{}
+	ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		query.Add(string(key), string(value))
+	})
+
+	return &ConnectRequest{
+		SessionKey: sessionKey,
+		header:     header.Get,
+		cookie: func(name string) string {
+			return cookies[name]
+		},
+		query: query.Get,
+	}
+}
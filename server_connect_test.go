@@ -0,0 +1,165 @@
+package golive
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type userKey struct{}
+
+type userPage struct {
+	LiveComponentWrapper
+	User string
+}
+
+func (p *userPage) Create(l *LiveComponent) {
+	p.LiveComponentWrapper.Create(l)
+
+	p.User, _ = l.Ctx().Value(userKey{}).(string)
+}
+
+func (p *userPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div>{{ .User }}</div>`
+}
+
+func newConnectTestServer(t *testing.T) (*LiveServer, *httptest.Server) {
+	liveServer := NewServer()
+	liveServer.Log = func(int, string, map[string]interface{}) {}
+
+	// The user of the page is the one of the token of the first request,
+	// connections must carry the same token
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), userKey{}, r.URL.Query().Get("token"))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	reauth := func(next ConnectHandler) ConnectHandler {
+		return func(r *ConnectRequest) error {
+			if r.Context.Value(userKey{}) != r.Query("token") {
+				return errors.New("token of another user")
+			}
+			return next(r)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", auth(liveServer.CreateHTTPHandlerWithContext(func(_ context.Context) *LiveComponent {
+		return NewLiveComponent("UserPage", &userPage{})
+	}, PageContent{})))
+	mux.Handle("/ws", liveServer.CreateWSHTTPHandlerWithMiddleware(reauth))
+	mux.Handle("/sse", liveServer.CreateSSEHTTPHandlerWithMiddleware(reauth))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return liveServer, server
+}
+
+func TestServer_PageContext(t *testing.T) {
+	liveServer, server := newConnectTestServer(t)
+
+	res, err := http.Get(server.URL + "?token=paul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	var session *Session
+	liveServer.Wire.Sessions.Range(func(_ string, s *Session) bool {
+		session = s
+		return false
+	})
+
+	if session == nil {
+		t.Fatal("session not created")
+	}
+
+	if session.Context().Err() != nil {
+		t.Error("page context cancelled with the request")
+	}
+
+	if session.Context().Value(userKey{}) != "paul" || session.LivePage.Context().Value(userKey{}) != "paul" {
+		t.Error("page context without the request values")
+	}
+
+	if user := session.LivePage.entry().component.(*userPage).User; user != "paul" {
+		t.Error("component without the request values", user)
+	}
+}
+
+func TestServer_ConnectMiddleware(t *testing.T) {
+	_, server := newConnectTestServer(t)
+
+	res, err := http.Get(server.URL + "?token=paul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	refused := dialHTTPTestServerPath(t, server, "/ws?token=emma", res.Cookies())
+
+	var msg PatchBrowser
+	if err := refused.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Type != EventLiveError || msg.Message != LiveErrorConnectRefused {
+		t.Error("connection of another user not refused", msg)
+	}
+
+	accepted := dialHTTPTestServerPath(t, server, "/ws?token=paul", res.Cookies())
+	readPatchOfType(t, accepted, EventLiveConnectElement)
+}
+
+func TestServer_ConnectMiddlewareSSE(t *testing.T) {
+	_, server := newConnectTestServer(t)
+
+	res, err := http.Get(server.URL + "?token=paul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	request := func(method, token string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+"/sse?token="+token, strings.NewReader("{}"))
+		for _, cookie := range res.Cookies() {
+			req.AddCookie(cookie)
+		}
+
+		stream, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = stream.Body.Close()
+		})
+
+		return stream
+	}
+
+	refused := readSSEMessage(t, bufio.NewReader(request(http.MethodGet, "emma").Body))
+	if refused.Type != EventLiveError || refused.Message != LiveErrorConnectRefused {
+		t.Error("event stream of another user not refused", refused)
+	}
+
+	accepted := readSSEMessage(t, bufio.NewReader(request(http.MethodGet, "paul").Body))
+	if accepted.Type != EventLiveConnectElement {
+		t.Fatal("expecting connect element message, received", accepted.Type)
+	}
+
+	if status := request(http.MethodPost, "emma").StatusCode; status != http.StatusForbidden {
+		t.Error("event of another user not refused, status code", status)
+	}
+
+	// The empty event is invalid, but reaches the session
+	if status := request(http.MethodPost, "paul").StatusCode; status != http.StatusBadRequest {
+		t.Error("unexpected event status code", status)
+	}
+}
@@ -0,0 +1,71 @@
+package golive
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// HandleHTTPRequest writes the first render of the component to a net/http
+// response, setting the session cookie used later by the websocket.
+func (s *LiveServer) HandleHTTPRequest(w http.ResponseWriter, r *http.Request, lc *LiveComponent, c PageContent) {
+
+	lr, err := s.HandleFirstRequestWithContext(r.Context(), lc, c, r.URL.Query())
+
+	if lr == nil {
+		s.Log(LogPanic, "no live page", logEx{"error": err})
+		return
+	}
+
+	if err != nil {
+		s.Log(LogError, "handle http request", logEx{"error": err})
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.CookieName,
+		Value:    lr.Session,
+		Path:     "/",
+		Expires:  time.Now().Add(24 * time.Hour),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = w.Write([]byte(lr.Rendered))
+}
+
+// CreateHTTPHandler net/http version of CreateHTMLHandler.
+func (s *LiveServer) CreateHTTPHandler(f func() *LiveComponent, c PageContent) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lc := f()
+		lc.log = s.Log
+
+		s.HandleHTTPRequest(w, r, lc, c)
+	})
+}
+
+// CreateRouteHTTPHandler net/http version of CreateRouteHandler.
+func (s *LiveServer) CreateRouteHTTPHandler(path string, f func() *LiveComponent, c PageContent) http.Handler {
+	s.Router.Handle(path, f)
+
+	return s.CreateHTTPHandler(f, c)
+}
+
+// CreateHTTPHandlerWithContext net/http version of
+// CreateHTMLHandlerWithMiddleware. The request context is used as the page
+// context, so any net/http middleware wrapping the handler can populate it.
+func (s *LiveServer) CreateHTTPHandlerWithContext(f func(ctx context.Context) *LiveComponent, c PageContent) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lc := f(r.Context())
+		lc.log = s.Log
+
+		s.HandleHTTPRequest(w, r, lc, c)
+	})
+}
+
+// CreateWSHTTPHandler net/http version of HandleWSRequest. It must be
+// mounted on the /ws path of the same host serving the pages.
+func (s *LiveServer) CreateWSHTTPHandler() http.Handler {
+	return s.CreateWSHTTPHandlerWithMiddleware()
+}
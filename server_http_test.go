@@ -0,0 +1,469 @@
+package golive
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+)
+
+type Counter struct {
+	LiveComponentWrapper
+	Count int
+}
+
+func (c *Counter) Increase() {
+	c.Count++
+}
+
+func (c *Counter) TemplateHandler(_ *LiveComponent) string {
+	return `<div><button go-live-click="Increase">{{ .Count }}</button></div>`
+}
+
+type About struct {
+	LiveComponentWrapper
+}
+
+func (a *About) Back() {
+	a.Component.Navigate("/")
+}
+
+func (a *About) TemplateHandler(_ *LiveComponent) string {
+	return `<div><button go-live-click="Back">About</button></div>`
+}
+
+func newHTTPTestServer(t *testing.T) (*LiveServer, *httptest.Server) {
+	liveServer := NewServer()
+	liveServer.Log = func(level int, message string, extra map[string]interface{}) {
+		if level >= LogError {
+			t.Log(message, extra)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", liveServer.CreateRouteHTTPHandler("/", func() *LiveComponent {
+		return NewLiveComponent("Counter", &Counter{})
+	}, PageContent{Lang: "us", Title: "Counter"}))
+	mux.Handle("/about", liveServer.CreateRouteHTTPHandler("/about", func() *LiveComponent {
+		return NewLiveComponent("About", &About{})
+	}, PageContent{Lang: "us", Title: "About"}))
+	mux.Handle("/ws", liveServer.CreateWSHTTPHandler())
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return liveServer, server
+}
+
+func dialHTTPTestServer(t *testing.T, server *httptest.Server, cookies []*http.Cookie) *websocket.Conn {
+	return dialHTTPTestServerPath(t, server, "/ws", cookies)
+}
+
+func dialHTTPTestServerPath(t *testing.T, server *httptest.Server, path string, cookies []*http.Cookie) *websocket.Conn {
+	header := http.Header{}
+	for _, cookie := range cookies {
+		header.Add("Cookie", cookie.String())
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	return conn
+}
+
+func TestServerHTTP_PageAndWebsocket(t *testing.T) {
+	_, server := newHTTPTestServer(t)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatal("unexpected status code", res.StatusCode)
+	}
+
+	if len(res.Cookies()) == 0 {
+		t.Fatal("session cookie not set")
+	}
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	var connect PatchBrowser
+	if err := conn.ReadJSON(&connect); err != nil {
+		t.Fatal(err)
+	}
+
+	if connect.Type != EventLiveConnectElement {
+		t.Fatal("expecting connect element message, received", connect.Type)
+	}
+
+	err = conn.WriteJSON(BrowserEvent{
+		Name:        EventLiveMethod,
+		ComponentID: connect.ComponentID,
+		MethodName:  "Increase",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var patch PatchBrowser
+	if err := conn.ReadJSON(&patch); err != nil {
+		t.Fatal(err)
+	}
+
+	if patch.Type != EventLiveDom || len(patch.Instructions) != 1 || patch.Instructions[0].Content != "1" {
+		t.Error("unexpected patch", patch)
+	}
+}
+
+func TestServerHTTP_WebsocketWithoutSession(t *testing.T) {
+	_, server := newHTTPTestServer(t)
+
+	conn := dialHTTPTestServer(t, server, nil)
+
+	var msg PatchBrowser
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Type != EventLiveError || msg.Message != LiveErrorSessionNotFound {
+		t.Error("expecting session not found error, received", msg)
+	}
+}
+
+func TestServerHTTP_ResumeSession(t *testing.T) {
+	_, server := newHTTPTestServer(t)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	var connect PatchBrowser
+	if err := conn.ReadJSON(&connect); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.WriteJSON(BrowserEvent{
+		Name:        EventLiveMethod,
+		ComponentID: connect.ComponentID,
+		MethodName:  "Increase",
+	})
+
+	var patch PatchBrowser
+	if err := conn.ReadJSON(&patch); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	_ = conn.Close()
+
+	conn = dialHTTPTestServer(t, server, res.Cookies())
+
+	var replay PatchBrowser
+	if err := conn.ReadJSON(&replay); err != nil {
+		t.Fatal(err)
+	}
+
+	if replay.Type != EventLiveDom || len(replay.Instructions) != 1 {
+		t.Fatal("expecting a full render patch, received", replay)
+	}
+
+	instruction := replay.Instructions[0]
+	if instruction.Type != Replace.toString() || !strings.Contains(instruction.Content, ">1</button>") {
+		t.Error("full render patch without the component state", instruction)
+	}
+}
+
+func TestServerHTTP_TakeOverSession(t *testing.T) {
+	_, server := newHTTPTestServer(t)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	previous := dialHTTPTestServer(t, server, res.Cookies())
+
+	var connect PatchBrowser
+	if err := previous.ReadJSON(&connect); err != nil {
+		t.Fatal(err)
+	}
+
+	// The previous connection is still open, as when half open
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	var replay PatchBrowser
+	if err := conn.ReadJSON(&replay); err != nil {
+		t.Fatal(err)
+	}
+
+	if replay.Type != EventLiveDom || len(replay.Instructions) != 1 {
+		t.Fatal("expecting a full render patch, received", replay)
+	}
+
+	_ = previous.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg PatchBrowser
+		if err := previous.ReadJSON(&msg); err != nil {
+			break
+		}
+	}
+}
+
+func TestServerHTTP_PingTimeout(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+	liveServer.PingInterval = 5 * time.Millisecond
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	var connect PatchBrowser
+	if err := conn.ReadJSON(&connect); err != nil {
+		t.Fatal(err)
+	}
+
+	var session *Session
+	liveServer.Wire.Sessions.Range(func(_ string, s *Session) bool {
+		session = s
+		return false
+	})
+
+	// Not reading, the pings are not answered
+	deadline := time.Now().Add(5 * time.Second)
+	for session.GetStatus() == SessionOpen {
+		if time.Now().After(deadline) {
+			t.Fatal("connection not answering pings kept open")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServerHTTP_SessionExpired(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+	liveServer.ResumeGracePeriod = time.Millisecond
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	var connect PatchBrowser
+	if err := conn.ReadJSON(&connect); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	_ = conn.Close()
+
+	for i := 0; i < 100 && liveServer.Wire.Sessions.Len() != 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if liveServer.Wire.Sessions.Len() != 0 {
+		t.Fatal("session not removed after grace period")
+	}
+
+	conn = dialHTTPTestServer(t, server, res.Cookies())
+
+	var msg PatchBrowser
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Type != EventLiveError || msg.Message != LiveErrorSessionNotFound {
+		t.Error("expecting session not found error, received", msg)
+	}
+}
+
+// readPatchOfType reads messages until one of the type, skipping the others
+func readPatchOfType(t *testing.T, conn *websocket.Conn, patchType string) PatchBrowser {
+	for {
+		var patch PatchBrowser
+		if err := conn.ReadJSON(&patch); err != nil {
+			t.Fatal(err)
+		}
+
+		if patch.Type == patchType {
+			return patch
+		}
+	}
+}
+
+func TestServerHTTP_Navigate(t *testing.T) {
+	_, server := newHTTPTestServer(t)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	counter := readPatchOfType(t, conn, EventLiveConnectElement)
+
+	_ = conn.WriteJSON(BrowserEvent{Name: EventLiveNavigate, URL: "/about?from=counter"})
+
+	nav := readPatchOfType(t, conn, EventLiveNavigate)
+
+	if nav.ComponentID != counter.ComponentID || nav.Message != "/about?from=counter" || len(nav.Instructions) != 1 {
+		t.Fatal("unexpected navigate patch", nav)
+	}
+
+	content := nav.Instructions[0].Content
+	if !strings.Contains(content, ">About</button>") || !strings.Contains(content, ComponentIdAttrKey+`="About_`) {
+		t.Fatal("navigate patch without the new component", content)
+	}
+
+	start := strings.Index(content, ComponentIdAttrKey+`="`) + len(ComponentIdAttrKey) + 2
+	aboutID := content[start : start+strings.Index(content[start:], `"`)]
+
+	// The new component is connected after the patch inserting it
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if connect := readPatchOfType(t, conn, EventLiveConnectElement); connect.ComponentID != aboutID {
+		t.Error("expecting the connect element of the new component, received", connect)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	// The new component navigates back by itself
+	_ = conn.WriteJSON(BrowserEvent{Name: EventLiveMethod, ComponentID: aboutID, MethodName: "Back"})
+
+	nav = readPatchOfType(t, conn, EventLiveNavigate)
+
+	if nav.ComponentID != aboutID || nav.Message != "/" || len(nav.Instructions) != 1 {
+		t.Fatal("unexpected navigate back patch", nav)
+	}
+
+	_ = conn.WriteJSON(BrowserEvent{Name: EventLiveNavigate, URL: "/missing"})
+
+	nav = readPatchOfType(t, conn, EventLiveNavigate)
+
+	if nav.Message != "/missing" || len(nav.Instructions) != 0 {
+		t.Error("expecting a navigate patch without instructions for routes not found", nav)
+	}
+}
+
+type panicPage struct {
+	LiveComponentWrapper
+}
+
+func (p *panicPage) Mounted(_ *LiveComponent) {
+	panic("mounted")
+}
+
+func (p *panicPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div>panic</div>`
+}
+
+type brokenPage struct {
+	LiveComponentWrapper
+	Form *LiveComponent
+}
+
+func (p *brokenPage) TemplateHandler(_ *LiveComponent) string {
+	return `<div>{{ render .Form }}</div>`
+}
+
+func TestServerHTTP_NavigateFailures(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+	liveServer.Log = func(int, string, map[string]interface{}) {}
+
+	liveServer.Router.Handle("/panic", func() *LiveComponent {
+		return NewLiveComponent("Panic", &panicPage{})
+	})
+	liveServer.Router.Handle("/broken", func() *LiveComponent {
+		return NewLiveComponent("Broken", &brokenPage{Form: NewLiveComponent("Limit", &limitForm{})})
+	})
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+	counter := readPatchOfType(t, conn, EventLiveConnectElement)
+
+	// The browser loads the pages failing to be created or mounted
+	for _, url := range []string{"/panic", "/broken"} {
+		_ = conn.WriteJSON(BrowserEvent{Name: EventLiveNavigate, URL: url})
+
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		nav := readPatchOfType(t, conn, EventLiveNavigate)
+		_ = conn.SetReadDeadline(time.Time{})
+
+		if nav.Message != url || len(nav.Instructions) != 0 {
+			t.Error("expecting a navigate patch without instructions, received", nav)
+		}
+	}
+
+	// The session keeps the page
+	_ = conn.WriteJSON(BrowserEvent{Name: EventLiveMethod, ComponentID: counter.ComponentID, MethodName: "Increase"})
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if patch := readPatchOfType(t, conn, EventLiveDom); patch.ComponentID != counter.ComponentID {
+		t.Error("unexpected patch after failed navigations", patch)
+	}
+}
+
+func TestServerHTTP_EventErrorCode(t *testing.T) {
+	_, server := newHTTPTestServer(t)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	connect := readPatchOfType(t, conn, EventLiveConnectElement)
+
+	_ = conn.WriteJSON(BrowserEvent{
+		Name:        EventLiveMethod,
+		ComponentID: connect.ComponentID,
+		MethodName:  "TemplateHandler",
+	})
+
+	if msg := readPatchOfType(t, conn, EventLiveError); msg.Message != LiveErrorNotExposed {
+		t.Error("expecting the not exposed code, received", msg.Message)
+	}
+}
+
+func TestLiveErrorOf(t *testing.T) {
+	pet := NewLiveComponent("pet", &PetMethods{})
+
+	for code, err := range map[string]error{
+		LiveErrorMethodFailed:   pet.InvokeMethodInPath("Rename", map[string]string{"name": ""}, nil),
+		LiveErrorInvalidEvent:   pet.InvokeMethodInPath("Unsupported", nil, nil),
+		LiveErrorUploadRejected: fmt.Errorf("upload: %w", ErrUploadTooLarge),
+	} {
+		if liveErrorOf(err) != code {
+			t.Error("expecting code", code, "for", err, "received", liveErrorOf(err))
+		}
+	}
+}
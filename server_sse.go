@@ -0,0 +1,338 @@
+package golive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	ErrSSEConnectionClosed = errors.New("sse connection closed")
+	ErrSessionNotOpen      = errors.New("session not open")
+	ErrCrossOrigin         = errors.New("cross origin event request")
+	ErrEventTooLarge       = errors.New("event request too large")
+	ErrEventRefused        = errors.New("event request refused")
+)
+
+const sseHeartbeatInterval = 15 * time.Second
+
+// SSEHeader is set in the responses of the Server-Sent Events transport,
+// telling the browser probing it that the server answers on /sse, unlike a
+// page not found.
+const SSEHeader = "Go-Live-Transport"
+
+const sseHeaderValue = "sse"
+
+// MaxEventRequestSize is the largest body of the event requests of the
+// Server-Sent Events transport, upload chunks included.
+const MaxEventRequestSize = 1 << 20
+
+// sseConnection is a LiveConnection over Server-Sent Events. It only
+// carries messages to the browser, browser events arrive through HTTP POST
+// requests handled by HandleEventRequest.
+type sseConnection struct {
+	mu    sync.Mutex
+	w     io.Writer
+	flush func() error
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	onClose   func(code int, text string) error
+}
+
+func newSSEConnection(w io.Writer, flush func() error) *sseConnection {
+	return &sseConnection{
+		w:      w,
+		flush:  flush,
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *sseConnection) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.write(fmt.Sprintf("data: %s\n\n", b))
+}
+
+func (c *sseConnection) write(s string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.closed:
+		return ErrSSEConnectionClosed
+	default:
+	}
+
+	if _, err := io.WriteString(c.w, s); err != nil {
+		go c.drop()
+		return err
+	}
+
+	if err := c.flush(); err != nil {
+		go c.drop()
+		return err
+	}
+
+	return nil
+}
+
+// ReadJSON blocks until the connection is closed, there is nothing to
+// read from the event stream.
+func (c *sseConnection) ReadJSON(_ interface{}) error {
+	<-c.closed
+	return ErrSSEConnectionClosed
+}
+
+func (c *sseConnection) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *sseConnection) SetCloseHandler(h func(code int, text string) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onClose = h
+}
+
+// drop is called when the browser went away.
+func (c *sseConnection) drop() {
+	c.mu.Lock()
+	onClose := c.onClose
+	c.mu.Unlock()
+
+	if onClose != nil {
+		_ = onClose(http.StatusGone, "event stream dropped")
+	}
+
+	_ = c.Close()
+}
+
+// heartbeat keeps proxies from timing out the stream and detects
+// browsers that went away without closing the request.
+func (c *sseConnection) heartbeat() {
+	ticker := time.NewTicker(sseHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			if err := c.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// checkEventRequest rejects the event requests of other sites, carrying
+// the session cookie of the browser, and the bodies above
+// MaxEventRequestSize. Requests without Origin nor Referer are not sent by
+// browsers on behalf of other sites.
+func checkEventRequest(host, origin, referer string, size int) error {
+	if size > MaxEventRequestSize {
+		return ErrEventTooLarge
+	}
+
+	source := origin
+	if source == "" {
+		source = referer
+	}
+
+	if source == "" {
+		return nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || u.Host != host {
+		return fmt.Errorf("%s: %w", source, ErrCrossOrigin)
+	}
+
+	return nil
+}
+
+// HandleEventRequest ingests a browser event sent through HTTP POST to a
+// session receiving its patches over Server-Sent Events.
+func (s *LiveServer) HandleEventRequest(sessionKey string, body []byte) error {
+	return s.handleEventRequest(&ConnectRequest{SessionKey: sessionKey}, body, nil)
+}
+
+// handleEventRequest is HandleEventRequest validating each request with
+// connect, when given, as the connection of the session was.
+func (s *LiveServer) handleEventRequest(r *ConnectRequest, body []byte, connect ConnectHandler) error {
+	sessionKey := r.SessionKey
+	session := s.Wire.GetSession(sessionKey)
+
+	if session == nil || session.GetStatus() != SessionOpen {
+		return ErrSessionNotOpen
+	}
+
+	if connect != nil {
+		r.Context = session.Context()
+
+		if err := connect(r); err != nil {
+			return fmt.Errorf("%w: %v", ErrEventRefused, err)
+		}
+	}
+
+	inMsg := BrowserEvent{}
+
+	if err := json.Unmarshal(body, &inMsg); err != nil {
+		return fmt.Errorf("unmarshal event: %w", err)
+	}
+
+	s.Log(LogDebug, "message in", logEx{"msg": inMsg, "session": sessionKey})
+
+	return session.IngestMessage(inMsg)
+}
+
+// HandleSSERequest is the Fiber handler of the Server-Sent Events
+// transport, used by the browser when websockets are not available. It
+// must be mounted for GET and POST on the /sse path.
+func (s *LiveServer) HandleSSERequest(ctx *fiber.Ctx) error {
+	return s.handleSSERequest(ctx, nil)
+}
+
+// CreateSSEHandlerWithMiddleware is HandleSSERequest running the
+// middlewares before attaching the event stream to its session, and
+// before ingesting each event request.
+func (s *LiveServer) CreateSSEHandlerWithMiddleware(middlewares ...ConnectMiddleware) func(ctx *fiber.Ctx) error {
+	connect := connectChain(middlewares)
+
+	return func(ctx *fiber.Ctx) error {
+		return s.handleSSERequest(ctx, connect)
+	}
+}
+
+func (s *LiveServer) handleSSERequest(ctx *fiber.Ctx, connect ConnectHandler) error {
+	r := fiberConnectRequest(ctx, ctx.Cookies(s.CookieName))
+
+	ctx.Set(SSEHeader, sseHeaderValue)
+
+	if ctx.Method() == fiber.MethodPost {
+		err := checkEventRequest(string(ctx.Request().Host()), ctx.Get(fiber.HeaderOrigin), ctx.Get(fiber.HeaderReferer), len(ctx.Body()))
+		if err == nil {
+			err = s.handleEventRequest(r, ctx.Body(), connect)
+		}
+
+		ctx.Status(s.eventResponseStatus(err))
+		return nil
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		c := newSSEConnection(w, w.Flush)
+		go c.heartbeat()
+
+		s.handleConnection(r, c, connect)
+	})
+
+	return nil
+}
+
+// CreateSSEHTTPHandler net/http version of HandleSSERequest.
+func (s *LiveServer) CreateSSEHTTPHandler() http.Handler {
+	return s.CreateSSEHTTPHandlerWithMiddleware()
+}
+
+// CreateSSEHTTPHandlerWithMiddleware net/http version of
+// CreateSSEHandlerWithMiddleware.
+func (s *LiveServer) CreateSSEHTTPHandlerWithMiddleware(middlewares ...ConnectMiddleware) http.Handler {
+	connect := connectChain(middlewares)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sessionKey string
+		if cookie, err := r.Cookie(s.CookieName); err == nil {
+			sessionKey = cookie.Value
+		}
+
+		w.Header().Set(SSEHeader, sseHeaderValue)
+
+		if r.Method == http.MethodPost {
+			body, err := io.ReadAll(io.LimitReader(r.Body, MaxEventRequestSize+1))
+			if err == nil {
+				err = checkEventRequest(r.Host, r.Header.Get("Origin"), r.Header.Get("Referer"), len(body))
+			}
+			if err == nil {
+				err = s.handleEventRequest(httpConnectRequest(r, sessionKey), body, connect)
+			}
+
+			w.WriteHeader(s.eventResponseStatus(err))
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			s.Log(LogError, "handle sse http request: response writer without flush", nil)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		c := newSSEConnection(w, func() error {
+			flusher.Flush()
+			return nil
+		})
+
+		go c.heartbeat()
+		go func() {
+			select {
+			case <-r.Context().Done():
+				c.drop()
+			case <-c.closed:
+			}
+		}()
+
+		s.handleConnection(httpConnectRequest(r, sessionKey), c, connect)
+	})
+}
+
+// eventResponseStatus logs the error of an event request and returns the
+// status code of its response.
+func (s *LiveServer) eventResponseStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusNoContent
+	case errors.Is(err, ErrSessionNotOpen):
+		s.Log(LogWarn, "handle event request: session not open", nil)
+		return http.StatusNotFound
+	case errors.Is(err, ErrEventRefused):
+		s.Log(LogWarn, "handle event request: refused", logEx{"error": err})
+		return http.StatusForbidden
+	case errors.Is(err, ErrCrossOrigin):
+		s.Log(LogWarn, "handle event request: cross origin", logEx{"error": err})
+		return http.StatusForbidden
+	case errors.Is(err, ErrEventTooLarge):
+		s.Log(LogWarn, "handle event request: too large", nil)
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrRateLimited):
+		s.Log(LogDebug, "handle event request: event dropped", logEx{"error": err})
+		return http.StatusTooManyRequests
+	default:
+		s.Log(LogError, "handle event request", logEx{"error": err})
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,160 @@
+package golive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func readSSEMessage(t *testing.T, r *bufio.Reader) PatchBrowser {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var msg PatchBrowser
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+			t.Fatal(err)
+		}
+
+		return msg
+	}
+}
+
+func TestServerSSE_StreamAndPost(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+
+	mux := server.Config.Handler.(*http.ServeMux)
+	mux.Handle("/sse", liveServer.CreateSSEHTTPHandler())
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/sse", nil)
+	for _, cookie := range res.Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	if stream.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatal("unexpected content type", stream.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(stream.Body)
+
+	connect := readSSEMessage(t, reader)
+	if connect.Type != EventLiveConnectElement {
+		t.Fatal("expecting connect element message, received", connect.Type)
+	}
+
+	body, _ := json.Marshal(BrowserEvent{
+		Name:        EventLiveMethod,
+		ComponentID: connect.ComponentID,
+		MethodName:  "Increase",
+	})
+
+	post, _ := http.NewRequest(http.MethodPost, server.URL+"/sse", bytes.NewReader(body))
+	for _, cookie := range res.Cookies() {
+		post.AddCookie(cookie)
+	}
+
+	postRes, err := http.DefaultClient.Do(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = postRes.Body.Close()
+
+	if postRes.StatusCode != http.StatusNoContent {
+		t.Fatal("unexpected post status code", postRes.StatusCode)
+	}
+
+	patch := readSSEMessage(t, reader)
+	if patch.Type != EventLiveDom || len(patch.Instructions) != 1 || patch.Instructions[0].Content != "1" {
+		t.Error("unexpected patch", patch)
+	}
+}
+
+func TestServerSSE_PostWithoutSession(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+
+	mux := server.Config.Handler.(*http.ServeMux)
+	mux.Handle("/sse", liveServer.CreateSSEHTTPHandler())
+
+	res, err := http.Post(server.URL+"/sse", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Error("expecting not found status code, received", res.StatusCode)
+	}
+
+	// Probed by the browser, telling it from a path not mounted
+	if res.Header.Get(SSEHeader) != "sse" {
+		t.Error("expecting the transport header, received", res.Header)
+	}
+}
+
+func TestServerSSE_PostRejected(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+
+	mux := server.Config.Handler.(*http.ServeMux)
+	mux.Handle("/sse", liveServer.CreateSSEHTTPHandler())
+
+	crossOrigin, _ := http.NewRequest(http.MethodPost, server.URL+"/sse", strings.NewReader("{}"))
+	crossOrigin.Header.Set("Content-Type", "text/plain")
+	crossOrigin.Header.Set("Origin", "https://evil.example")
+
+	tooLarge, _ := http.NewRequest(http.MethodPost, server.URL+"/sse", strings.NewReader(strings.Repeat(" ", MaxEventRequestSize+1)))
+
+	for req, status := range map[*http.Request]int{
+		crossOrigin: http.StatusForbidden,
+		tooLarge:    http.StatusRequestEntityTooLarge,
+	} {
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.Body.Close()
+
+		if res.StatusCode != status {
+			t.Error("expecting status code", status, "received", res.StatusCode)
+		}
+	}
+}
+
+func TestCheckEventRequest(t *testing.T) {
+	for _, c := range []struct {
+		origin, referer string
+		err             error
+	}{
+		{"", "", nil},
+		{"http://example.com", "", nil},
+		{"", "http://example.com/page", nil},
+		{"http://evil.example", "http://example.com/page", ErrCrossOrigin},
+		{"", "http://evil.example/page", ErrCrossOrigin},
+		{"null", "", ErrCrossOrigin},
+	} {
+		if err := checkEventRequest("example.com", c.origin, c.referer, 0); !errors.Is(err, c.err) {
+			t.Error("origin", c.origin, "referer", c.referer, "expecting", c.err, "received", err)
+		}
+	}
+}
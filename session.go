@@ -1,13 +1,25 @@
 package golive
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	EventLiveInput          = "li"
 	EventLiveMethod         = "lm"
+	EventLiveSubmit         = "ls"
+	EventLiveUpload         = "lu"
+	EventLiveUploadChunk    = "luc"
+	EventLiveNavigate       = "ln"
+	EventLiveQuery          = "lq"
+	EventLiveBatch          = "lb"
 	EventLiveDom            = "ld"
 	EventLiveDisconnect     = "lx"
 	EventLiveError          = "le"
@@ -16,26 +28,91 @@ const (
 
 var (
 	LiveErrorSessionNotFound = "session_not_found"
+	LiveErrorServerShutdown  = "server_shutdown"
+	LiveErrorConnectRefused  = "connect_refused"
+	LiveErrorNotExposed      = "not_exposed"
+	LiveErrorUploadRejected  = "upload_rejected"
+	LiveErrorMethodFailed    = "method_failed"
+	LiveErrorInvalidEvent    = "invalid_event"
+	LiveErrorRateLimited     = "rate_limited"
 )
 
 func LiveErrorMap() map[string]string {
 	return map[string]string{
 		"LiveErrorSessionNotFound": LiveErrorSessionNotFound,
+		"LiveErrorServerShutdown":  LiveErrorServerShutdown,
+		"LiveErrorConnectRefused":  LiveErrorConnectRefused,
+		"LiveErrorNotExposed":      LiveErrorNotExposed,
+		"LiveErrorUploadRejected":  LiveErrorUploadRejected,
+		"LiveErrorMethodFailed":    LiveErrorMethodFailed,
+		"LiveErrorInvalidEvent":    LiveErrorInvalidEvent,
+		"LiveErrorRateLimited":     LiveErrorRateLimited,
+	}
+}
+
+// liveErrorOf returns the code telling the browser about the error of its
+// event. The error itself, which may tell about the internals of the
+// components, only goes to the server log.
+func liveErrorOf(err error) string {
+	var methodErr *methodError
+
+	switch {
+	case errors.Is(err, ErrNotExposed):
+		return LiveErrorNotExposed
+	case errors.Is(err, ErrUploadNotFound), errors.Is(err, ErrUploadTooLarge),
+		errors.Is(err, ErrUploadType), errors.Is(err, ErrUploadChunk),
+		errors.Is(err, ErrUploadsInFlight), errors.Is(err, ErrUploadStalled):
+		return LiveErrorUploadRejected
+	case errors.As(err, &methodErr):
+		return LiveErrorMethodFailed
+	default:
+		return LiveErrorInvalidEvent
 	}
 }
 
 type BrowserEvent struct {
-	Name        string            `json:"name"`
-	ComponentID string            `json:"component_id"`
-	MethodName  string            `json:"method_name"`
-	MethodData  map[string]string `json:"method_data"`
-	StateKey    string            `json:"key"`
-	StateValue  string            `json:"value"`
-	DOMEvent    *DOMEvent         `json:"dom_event"`
+	Name        string              `json:"name"`
+	ComponentID string              `json:"component_id"`
+	MethodName  string              `json:"method_name"`
+	MethodData  map[string]string   `json:"method_data"`
+	FormData    map[string][]string `json:"form_data"`
+	StateKey    string              `json:"key"`
+	StateValue  string              `json:"value"`
+	DOMEvent    *DOMEvent           `json:"dom_event"`
+	Upload      *BrowserUpload      `json:"upload"`
+	URL         string              `json:"url"`
 }
 
+// DOMEvent is the browser event invoking a method. Only the properties
+// the event has are set, as ClientX for mouse events or Value for the
+// events of inputs.
 type DOMEvent struct {
-	KeyCode string `json:"keyCode"`
+	// Type is the name of the event, as change or dragstart
+	Type string `json:"type"`
+
+	KeyCode  string `json:"keyCode"`
+	Key      string `json:"key"`
+	AltKey   bool   `json:"altKey"`
+	CtrlKey  bool   `json:"ctrlKey"`
+	MetaKey  bool   `json:"metaKey"`
+	ShiftKey bool   `json:"shiftKey"`
+
+	Button  int     `json:"button"`
+	ClientX float64 `json:"clientX"`
+	ClientY float64 `json:"clientY"`
+	DeltaX  float64 `json:"deltaX"`
+	DeltaY  float64 `json:"deltaY"`
+
+	// ScrollTop and ScrollLeft are the scroll of the element
+	ScrollTop  float64 `json:"scrollTop"`
+	ScrollLeft float64 `json:"scrollLeft"`
+
+	// Value and Checked are the state of the input
+	Value   string `json:"value"`
+	Checked bool   `json:"checked"`
+
+	// Data is the text dragged, of drag and drop events
+	Data string `json:"data"`
 }
 
 type SessionStatus string
@@ -44,6 +121,8 @@ const (
 	SessionNew    SessionStatus = "n"
 	SessionOpen   SessionStatus = "o"
 	SessionClosed SessionStatus = "c"
+	// SessionExpired is a closed session that was not resumed in time
+	SessionExpired SessionStatus = "e"
 )
 
 type Session struct {
@@ -51,19 +130,255 @@ type Session struct {
 	OutChannel chan PatchBrowser
 	log        Log
 	Status     SessionStatus
+
+	router *LiveRouter
+	pubsub PubSubBackend
+	queue  sessionQueue
+
+	limiter *eventLimiter
+
+	// ctx is the parent of the contexts of the components, cancelled when
+	// the session ends
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// FrameInterval is how long the updates of components are collected
+	// before rendering them together, see updateBatch. Zero renders each
+	// update as it arrives.
+	FrameInterval time.Duration
+
+	mu     sync.Mutex
+	expire *time.Timer
+	conn   *sessionConnection
+
+	hangup     chan struct{}
+	hangupOnce sync.Once
 }
 
 func NewSession() *Session {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Session{
-		OutChannel: make(chan PatchBrowser),
+		ctx:        ctx,
+		cancel:     cancel,
+		OutChannel: make(chan PatchBrowser, DefaultQueueSize),
 		Status:     SessionNew,
+		queue:      newSessionQueue(QueueDropResync),
+		hangup:     make(chan struct{}),
 	}
 }
 
-func (s *Session) QueueMessage(message PatchBrowser) {
-	go func() {
-		s.OutChannel <- message
+// Context returns the page context of the session, with the values of the
+// context of its first request. It is cancelled when the session ends.
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// setContext sets the page context of a session not activated yet.
+func (s *Session) setContext(ctx context.Context) {
+	s.cancel()
+	s.ctx, s.cancel = context.WithCancel(detachedContext{parent: ctx})
+}
+
+// detachedContext keeps the values of a request context, but not its
+// cancellation, which happens when the request ends.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// GetStatus returns the session status, safe to call from any goroutine.
+func (s *Session) GetStatus() SessionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Status
+}
+
+// sessionConnection is the connection attached to an open session.
+type sessionConnection struct {
+	// close ends the connection, closed is closed once the connection
+	// released the session
+	close  func()
+	closed <-chan struct{}
+}
+
+// open marks the session as open to a new connection. A closed session
+// is resumed if it did not expire yet.
+func (s *Session) open(conn *sessionConnection) (resumed bool, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.Status {
+	case SessionNew:
+	case SessionClosed:
+		resumed = true
+	default:
+		return false, false
+	}
+
+	if s.expire != nil {
+		s.expire.Stop()
+		s.expire = nil
+	}
+
+	s.Status = SessionOpen
+	s.conn = conn
+
+	return resumed, true
+}
+
+// takeOver ends the connection attached to the open session, waiting for
+// it to release the session. A browser reconnecting while its previous
+// connection is half open takes its place. It reports false when there is
+// no connection to end.
+func (s *Session) takeOver() bool {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+
+	if conn == nil {
+		return false
+	}
+
+	conn.close()
+	<-conn.closed
+
+	return true
+}
+
+// close marks the session as closed. If the session is not opened again
+// within grace, it expires and onExpire is called.
+func (s *Session) close(grace time.Duration, onExpire func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn = nil
+
+	// Already ended by the server
+	if s.Status == SessionExpired {
+		return
+	}
+
+	if grace <= 0 {
+		s.Status = SessionExpired
+		go onExpire()
+		return
+	}
+
+	s.Status = SessionClosed
+	s.expire = time.AfterFunc(grace, func() {
+		s.mu.Lock()
+		if s.Status != SessionClosed {
+			s.mu.Unlock()
+			return
+		}
+		s.Status = SessionExpired
+		s.expire = nil
+		s.mu.Unlock()
+
+		onExpire()
+	})
+}
+
+// Resume prepares a resumed session to its new connection. The patches
+// not delivered to the old connection are discarded and replaced by a
+// full render of the page, rendered in the page events goroutine.
+func (s *Session) Resume() {
+	// Draining before locking releases a sender blocked by QueueBlock
+	s.drainQueue()
+
+	s.queue.mu.Lock()
+	s.drainQueue()
+	s.queue.pending = nil
+	s.queue.resyncing = true
+	s.LivePage.Emit(PageResync, nil)
+	s.queue.mu.Unlock()
+
+	select {
+	case <-s.queue.overflow:
+	default:
+	}
+}
+
+// Navigate swaps the entry component of the page by the component of the
+// route of url, as a go-live-link does. The browser shows the new
+// component and pushes url to its history.
+func (s *Session) Navigate(url string) {
+	s.LivePage.Navigate(url)
+}
+
+// navigate runs in the page events goroutine. When the url has no route,
+// the browser is told to load it.
+func (s *Session) navigate(rawURL string) (err error) {
+	patch := NewPatchBrowser(s.LivePage.entry().Name)
+	patch.Type = EventLiveNavigate
+	patch.Message = rawURL
+
+	// Hooks of the route component panicking do not end the session, the
+	// browser loads the url instead
+	defer func() {
+		if payload := recover(); payload != nil {
+			s.QueueMessage(*patch)
+			err = fmt.Errorf("navigate %s: panic: %v", rawURL, payload)
+		}
 	}()
+
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		s.QueueMessage(*patch)
+		return fmt.Errorf("navigate: parse url: %w", err)
+	}
+
+	if s.router == nil {
+		s.QueueMessage(*patch)
+		return fmt.Errorf("navigate %s: %w", u.Path, ErrRouteNotFound)
+	}
+
+	lc, err := s.router.Match(u.Path)
+
+	if err != nil {
+		s.QueueMessage(*patch)
+		return fmt.Errorf("navigate: %w", err)
+	}
+
+	lc.log = s.log
+	lc.pubsub = s.pubsub
+	lc.ctx = s.ctx
+	lc.bindQuery(u.Query())
+
+	rendered, err := s.LivePage.swapEntryComponent(lc)
+
+	if err != nil {
+		s.QueueMessage(*patch)
+		return fmt.Errorf("navigate %s: %w", rawURL, err)
+	}
+
+	patch.AddInstruction(PatchInstruction{
+		Name:    EventLiveNavigate,
+		Type:    Replace.toString(),
+		Content: rendered,
+	})
+
+	s.QueueMessage(*patch)
+
+	return nil
 }
 
 func (s *Session) IngestMessage(message BrowserEvent) error {
@@ -76,9 +391,27 @@ func (s *Session) IngestMessage(message BrowserEvent) error {
 		}
 	}()
 
-	err := s.LivePage.HandleBrowserEvent(message)
+	if !s.allowEvent(message) {
+		s.dropEvent(message)
+		return fmt.Errorf("%s: %w", message.Name, ErrRateLimited)
+	}
+
+	if message.Name == EventLiveNavigate {
+		s.Navigate(message.URL)
+		return nil
+	}
+
+	err := s.handleEvent(message)
 
 	if err != nil {
+		// The browser is told about the error, so it can be handled
+		// by the page script
+		s.QueueMessage(PatchBrowser{
+			ComponentID: message.ComponentID,
+			Type:        EventLiveError,
+			Message:     liveErrorOf(err),
+		})
+
 		return err
 	}
 
@@ -86,35 +419,160 @@ func (s *Session) IngestMessage(message BrowserEvent) error {
 }
 
 func (s *Session) ActivatePage(lp *Page) {
+	s.mu.Lock()
 	s.LivePage = lp
+	expired := s.Status == SessionExpired
+	s.mu.Unlock()
+
+	lp.ctx = s.ctx
+
+	// Ended by Shutdown before being activated
+	if expired {
+		lp.Close()
+		s.cancel()
+		return
+	}
+
+	lp.start()
 
 	// Here is the location that get all the components updates *notified* by
 	// the page!
 	go func() {
+		batch := newUpdateBatch()
+		var frame <-chan time.Time
+
 		for {
+			select {
+			case <-frame:
+				frame = nil
+				s.renderBatch(batch)
 			// Receive all the events from page
-			evt := <-s.LivePage.Events
-
-			s.log(LogDebug, fmt.Sprintf("Component %s triggering %d", evt.Component.Name, evt.Type), logEx{"evt": evt})
+			case evt := <-s.LivePage.Events:
+				s.log(LogDebug, fmt.Sprintf("Component %s triggering %d", evt.Component.Name, evt.Type), logEx{"evt": evt})
+
+				if evt.Type == PageComponentUpdated {
+					if evt.Component.Exited {
+						break
+					}
+
+					batch.add(evt.Component, evt.Source)
+
+					if s.FrameInterval <= 0 {
+						s.renderBatch(batch)
+					} else if frame == nil {
+						frame = time.After(s.FrameInterval)
+					}
+					break
+				}
 
-			switch evt.Type {
-			case PageComponentUpdated:
-				if err := s.LiveRenderComponent(evt.Component, evt.Source); err != nil {
-					s.log(LogError, "entryComponent live render", logEx{"error": err})
+				// The pending updates are rendered before the other events,
+				// keeping their order
+				frame = nil
+				s.renderBatch(batch)
+
+				switch evt.Type {
+				case PageNavigate:
+					if err := s.navigate(evt.Source.Value); err != nil {
+						s.log(LogError, "page navigate", logEx{"error": err})
+					}
+					break
+				case PageClose:
+					if entry := s.LivePage.entry(); !entry.Exited {
+						if err := entry.Kill(); err != nil {
+							s.log(LogError, "page close: kill entry component", logEx{"error": err})
+						}
+					}
+
+					s.LivePage.Close()
+					s.cancel()
+					return
+				case PageRun:
+					s.run(evt.Run)
+					break
+				case PageResync:
+					s.resyncingEvents()
+
+					if err := s.resync(); err != nil {
+						s.log(LogError, "page resync", logEx{"error": err})
+					}
+					break
+				case PageComponentMounted:
+					s.LivePage.markConnected(evt.Component.Name)
+
+					s.QueueMessage(PatchBrowser{
+						ComponentID:  evt.Component.Name,
+						Type:         EventLiveConnectElement,
+						Instructions: nil,
+					})
+					break
 				}
-				break
-			case PageComponentMounted:
-				s.QueueMessage(PatchBrowser{
-					ComponentID:  evt.Component.Name,
-					Type:         EventLiveConnectElement,
-					Instructions: nil,
-				})
-				break
 			}
 		}
 	}()
 }
 
+// firstRender mounts and renders the page in the goroutine of its events,
+// where the tickers and subscriptions started by Mounted hooks run.
+func (s *Session) firstRender(lp *Page) (rendered string, err error) {
+	done := make(chan struct{})
+
+	lp.Run(func() {
+		defer close(done)
+		defer func() {
+			if payload := recover(); payload != nil {
+				err = fmt.Errorf("mount page: %v", payload)
+			}
+		}()
+
+		lp.Mount()
+		rendered, err = lp.Render()
+	})
+
+	select {
+	case <-done:
+		return rendered, err
+	case <-lp.done:
+		return "", ErrServerShutdown
+	}
+}
+
+// handleEvent handles the browser event in the goroutine of the page
+// events, so it does not race with the renders, tickers and subscriptions.
+func (s *Session) handleEvent(message BrowserEvent) (err error) {
+	lp := s.LivePage
+	done := make(chan struct{})
+
+	lp.Run(func() {
+		defer close(done)
+		defer func() {
+			if payload := recover(); payload != nil {
+				s.log(LogWarn, fmt.Sprintf("ingest message: recover from panic: %v", payload), logEx{"message": message})
+			}
+		}()
+
+		err = lp.HandleBrowserEvent(message)
+	})
+
+	select {
+	case <-done:
+		return err
+	case <-lp.done:
+		return ErrServerShutdown
+	}
+}
+
+// run calls the function of a PageRun event, a panic of the function does
+// not stop the page.
+func (s *Session) run(fn func()) {
+	defer func() {
+		if payload := recover(); payload != nil {
+			s.log(LogError, fmt.Sprintf("page run panic recovered: %v", payload), nil)
+		}
+	}()
+
+	fn()
+}
+
 func (s *Session) generateBrowserPatchesFromDiff(diff *diff, source *EventSource) ([]*PatchBrowser, error) {
 
 	bp := make([]*PatchBrowser, 0)
@@ -181,23 +639,51 @@ func skipUpdateValueOnInput(in changeInstruction, source *EventSource) bool {
 // LiveRenderComponent render the updated Component and compare with
 // last state. It may apply with *all child components*
 func (s *Session) LiveRenderComponent(c *LiveComponent, source *EventSource) error {
+	patches, err := s.renderComponentPatches(c, source)
+
+	for _, om := range patches {
+		s.QueueMessage(om)
+	}
+
+	return err
+}
+
+// renderComponentPatches renders the component, returning the patches of
+// the browser to follow the render.
+func (s *Session) renderComponentPatches(c *LiveComponent, source *EventSource) ([]PatchBrowser, error) {
 	var err error
 
 	diff, err := c.LiveRender()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	patches, err := s.generateBrowserPatchesFromDiff(diff, source)
+	bp, err := s.generateBrowserPatchesFromDiff(diff, source)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, om := range patches {
-		s.QueueMessage(*om)
+	patches := make([]PatchBrowser, 0, len(bp)+1)
+
+	for _, om := range bp {
+		patches = append(patches, *om)
 	}
 
-	return nil
+	if query := c.queryUpdates(); query != nil {
+		encoded, err := json.Marshal(query)
+
+		if err != nil {
+			return patches, fmt.Errorf("marshal query: %w", err)
+		}
+
+		patches = append(patches, PatchBrowser{
+			ComponentID: c.Name,
+			Type:        EventLiveQuery,
+			Message:     string(encoded),
+		})
+	}
+
+	return patches, nil
 }
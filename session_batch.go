@@ -0,0 +1,143 @@
+package golive
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultFrameInterval renders the updates of a session up to 60 times a
+// second.
+const DefaultFrameInterval = time.Second / 60
+
+// updateBatch collects the components updated within a frame. A component
+// updated many times in the frame is rendered once.
+type updateBatch struct {
+	components []*LiveComponent
+	sources    map[*LiveComponent]*EventSource
+}
+
+func newUpdateBatch() *updateBatch {
+	return &updateBatch{
+		sources: make(map[*LiveComponent]*EventSource),
+	}
+}
+
+func (b *updateBatch) add(c *LiveComponent, source *EventSource) {
+	previous, ok := b.sources[c]
+
+	if !ok {
+		b.components = append(b.components, c)
+		b.sources[c] = source
+		return
+	}
+
+	// Updates from different sources render as a plain update
+	if previous == nil || source == nil || *previous != *source {
+		b.sources[c] = nil
+	}
+}
+
+func (b *updateBatch) reset() {
+	b.components = nil
+	b.sources = make(map[*LiveComponent]*EventSource)
+}
+
+// renderBatch renders the components of the batch, parents first, sending
+// their patches in a single message. The children rendered again by their
+// parents are not rendered by themselves.
+func (s *Session) renderBatch(b *updateBatch) {
+	if len(b.components) == 0 {
+		return
+	}
+
+	components := b.components
+	sources := b.sources
+	b.reset()
+
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].depth() < components[j].depth()
+	})
+
+	renders := make(map[*LiveComponent]int, len(components))
+	for _, c := range components {
+		renders[c] = c.renders
+	}
+
+	rendered := make(map[*LiveComponent]bool, len(components))
+	patches := make([]PatchBrowser, 0, len(components))
+
+	for _, c := range components {
+		if c.Exited {
+			continue
+		}
+
+		source := sources[c]
+
+		if c.hasAncestorIn(rendered) && c.renders != renders[c] {
+			continue
+		}
+
+		// The source of a child skipped with its parent still applies
+		if source == nil {
+			for _, other := range components {
+				if sources[other] != nil && other.hasAncestor(c) {
+					source = sources[other]
+					break
+				}
+			}
+		}
+
+		componentPatches, err := s.renderComponentPatches(c, source)
+
+		if err != nil {
+			s.log(LogError, "entryComponent live render", logEx{"error": err})
+		}
+
+		rendered[c] = true
+		patches = append(patches, componentPatches...)
+	}
+
+	s.queuePatches(patches)
+}
+
+// queuePatches sends the patches to the browser, batched in one message
+// when there are many.
+func (s *Session) queuePatches(patches []PatchBrowser) {
+	switch len(patches) {
+	case 0:
+	case 1:
+		s.QueueMessage(patches[0])
+	default:
+		s.QueueMessage(PatchBrowser{
+			Type:    EventLiveBatch,
+			Patches: patches,
+		})
+	}
+}
+
+// depth is how many parents the component has.
+func (l *LiveComponent) depth() int {
+	depth := 0
+	for parent := l.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+	return depth
+}
+
+func (l *LiveComponent) hasAncestor(ancestor *LiveComponent) bool {
+	for parent := l.parent; parent != nil; parent = parent.parent {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *LiveComponent) hasAncestorIn(components map[*LiveComponent]bool) bool {
+	for parent := l.parent; parent != nil; parent = parent.parent {
+		if components[parent] {
+			return true
+		}
+	}
+	return false
+}
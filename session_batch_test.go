@@ -0,0 +1,96 @@
+package golive
+
+import (
+	"testing"
+	"time"
+)
+
+type batchItem struct {
+	LiveComponentWrapper
+	Count int
+}
+
+func (i *batchItem) TemplateHandler(_ *LiveComponent) string {
+	return `<p>{{ .Count }}</p>`
+}
+
+type batchList struct {
+	LiveComponentWrapper
+	Count int
+	Item  *LiveComponent
+}
+
+func (l *batchList) TemplateHandler(_ *LiveComponent) string {
+	return `<div><span>{{ .Count }}</span>{{ render .Item }}</div>`
+}
+
+func TestSession_RenderBatch(t *testing.T) {
+	item := &batchItem{}
+	list := &batchList{Item: NewLiveComponent("Item", item)}
+
+	c := NewLiveComponent("List", list)
+	c.log = NewLoggerBasic().Log
+
+	if err := c.Create(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSession()
+	s.log = c.log
+
+	batch := newUpdateBatch()
+
+	item.Count = 1
+	batch.add(list.Item, nil)
+	batch.add(list.Item, nil)
+	list.Count = 1
+	batch.add(c, nil)
+
+	renders := list.Item.renders
+
+	s.renderBatch(batch)
+
+	if list.Item.renders != renders+1 {
+		t.Error("child rendered again after its parent, renders", list.Item.renders-renders)
+	}
+
+	select {
+	case msg := <-s.OutChannel:
+		if msg.Type != EventLiveBatch || len(msg.Patches) != 2 {
+			t.Fatal("patches not sent in one message", msg)
+		}
+
+		if msg.Patches[0].ComponentID != c.Name || msg.Patches[1].ComponentID != list.Item.Name {
+			t.Error("unexpected patches", msg.Patches)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("patches not sent")
+	}
+
+	if len(batch.components) != 0 {
+		t.Error("batch not reset", batch.components)
+	}
+}
+
+func TestUpdateBatch_AddSources(t *testing.T) {
+	c := NewLiveComponent("Item", &batchItem{})
+	input := &EventSource{Type: EventSourceInput, Value: "Count"}
+
+	batch := newUpdateBatch()
+	batch.add(c, input)
+	batch.add(c, &EventSource{Type: EventSourceInput, Value: "Count"})
+
+	if len(batch.components) != 1 || batch.sources[c] == nil {
+		t.Fatal("same source not kept", batch.components, batch.sources[c])
+	}
+
+	batch.add(c, nil)
+
+	if batch.sources[c] != nil {
+		t.Error("different sources kept", batch.sources[c])
+	}
+}
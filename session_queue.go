@@ -0,0 +1,286 @@
+package golive
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultQueueSize is how many messages a session keeps for its
+// connection before applying its QueuePolicy.
+const DefaultQueueSize = 256
+
+// QueuePolicy is what a session does with a message when its queue is
+// full, because the browser is not reading as fast as the page changes.
+type QueuePolicy int
+
+const (
+	// QueueDropResync drops the queued patches and sends a full render of
+	// the page instead. The other messages are kept.
+	QueueDropResync QueuePolicy = iota
+	// QueueBlock waits until the connection reads from the queue, slowing
+	// the page down to the pace of the browser.
+	QueueBlock
+	// QueueDisconnect drops the message and closes the connection. The
+	// browser resumes the session when it reconnects.
+	QueueDisconnect
+)
+
+// QueueStats are the metrics of the outbound queue of a session.
+type QueueStats struct {
+	Capacity int
+	// Depth is how many messages are waiting for the connection
+	Depth int
+	// MaxDepth is the highest depth reached
+	MaxDepth int
+	// Dropped is how many messages were dropped
+	Dropped uint64
+	// Overflows is how many times the queue was full
+	Overflows uint64
+}
+
+// sessionQueue keeps the messages of a session in order, in its
+// OutChannel, until the connection sends them.
+type sessionQueue struct {
+	mu     sync.Mutex
+	policy QueuePolicy
+
+	maxDepth  int64
+	dropped   uint64
+	overflows uint64
+
+	// resyncing is set while the page renders the full page replacing the
+	// dropped patches, keeping the other messages in pending
+	resyncing bool
+	pending   []PatchBrowser
+
+	overflow  chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newSessionQueue(policy QueuePolicy) sessionQueue {
+	return sessionQueue{
+		policy:   policy,
+		overflow: make(chan struct{}, 1),
+		closed:   make(chan struct{}),
+	}
+}
+
+// setQueue replaces the queue of a session not connected yet.
+func (s *Session) setQueue(size int, policy QueuePolicy) {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+
+	s.OutChannel = make(chan PatchBrowser, size)
+	s.queue = newSessionQueue(policy)
+}
+
+// QueueMessage sends the message to the browser, after the messages
+// queued before it.
+func (s *Session) QueueMessage(message PatchBrowser) {
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+
+	s.enqueue(message)
+}
+
+// enqueue runs with the queue locked.
+func (s *Session) enqueue(message PatchBrowser) {
+	q := &s.queue
+
+	// The patches rendered before the full page are dropped
+	if q.resyncing {
+		if isPatchMessage(message) {
+			atomic.AddUint64(&q.dropped, 1)
+		}
+
+		q.pending = append(q.pending, keptMessages(message)...)
+		return
+	}
+
+	select {
+	case <-q.closed:
+		atomic.AddUint64(&q.dropped, 1)
+		return
+	default:
+	}
+
+	select {
+	case s.OutChannel <- message:
+		s.trackDepth()
+		return
+	default:
+	}
+
+	atomic.AddUint64(&q.overflows, 1)
+
+	switch q.policy {
+	case QueueBlock:
+		select {
+		case s.OutChannel <- message:
+			s.trackDepth()
+		case <-q.closed:
+			atomic.AddUint64(&q.dropped, 1)
+		}
+	case QueueDisconnect:
+		atomic.AddUint64(&q.dropped, 1)
+
+		select {
+		case q.overflow <- struct{}{}:
+		default:
+		}
+	default:
+		s.startResync(message)
+	}
+}
+
+// startResync drops the queued patches, asking the page to render itself
+// in full. It runs with the queue locked.
+func (s *Session) startResync(message PatchBrowser) {
+	q := &s.queue
+
+	queued := s.drainQueue()
+	queued = append(queued, message)
+
+	for _, m := range queued {
+		if isPatchMessage(m) {
+			atomic.AddUint64(&q.dropped, 1)
+		}
+
+		q.pending = append(q.pending, keptMessages(m)...)
+	}
+
+	if s.LivePage == nil {
+		return
+	}
+
+	q.resyncing = true
+
+	s.LivePage.Emit(PageResync, nil)
+}
+
+// resync runs in the page events goroutine, replacing the dropped patches
+// by a full render of the page.
+func (s *Session) resync() error {
+	patch, err := s.LivePage.renderEntryPatch()
+
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+
+	pending := s.queue.pending
+	s.queue.pending = nil
+	s.queue.resyncing = false
+
+	connects := make([]PatchBrowser, 0)
+
+	for _, m := range pending {
+		// The components are connected once they are in the page
+		if m.Type == EventLiveConnectElement {
+			connects = append(connects, m)
+			continue
+		}
+
+		s.enqueue(m)
+	}
+
+	if err == nil {
+		s.enqueue(*patch)
+	}
+
+	for _, m := range connects {
+		s.enqueue(m)
+	}
+
+	return err
+}
+
+// drainQueue removes the messages waiting in the queue.
+func (s *Session) drainQueue() []PatchBrowser {
+	drained := make([]PatchBrowser, 0, len(s.OutChannel))
+
+	for {
+		select {
+		case m := <-s.OutChannel:
+			drained = append(drained, m)
+		default:
+			return drained
+		}
+	}
+}
+
+func (s *Session) trackDepth() {
+	depth := int64(len(s.OutChannel))
+
+	for {
+		max := atomic.LoadInt64(&s.queue.maxDepth)
+		if depth <= max || atomic.CompareAndSwapInt64(&s.queue.maxDepth, max, depth) {
+			return
+		}
+	}
+}
+
+// closeQueue drops the messages queued after the session ended, and
+// releases the senders blocked by QueueBlock.
+func (s *Session) closeQueue() {
+	s.queue.closeOnce.Do(func() {
+		close(s.queue.closed)
+	})
+}
+
+// QueueStats returns the metrics of the outbound queue.
+func (s *Session) QueueStats() QueueStats {
+	return QueueStats{
+		Capacity:  cap(s.OutChannel),
+		Depth:     len(s.OutChannel),
+		MaxDepth:  int(atomic.LoadInt64(&s.queue.maxDepth)),
+		Dropped:   atomic.LoadUint64(&s.queue.dropped),
+		Overflows: atomic.LoadUint64(&s.queue.overflows),
+	}
+}
+
+// QueueStats returns the metrics of the queues of every session, the
+// depths and counters summed and MaxDepth the highest of them.
+func (s *LiveServer) QueueStats() QueueStats {
+	var stats QueueStats
+
+	s.Wire.Sessions.Range(func(_ string, session *Session) bool {
+		ss := session.QueueStats()
+
+		stats.Capacity += ss.Capacity
+		stats.Depth += ss.Depth
+		stats.Dropped += ss.Dropped
+		stats.Overflows += ss.Overflows
+
+		if ss.MaxDepth > stats.MaxDepth {
+			stats.MaxDepth = ss.MaxDepth
+		}
+
+		return true
+	})
+
+	return stats
+}
+
+// isPatchMessage reports if the message changes the DOM of components,
+// which a full render replaces.
+func isPatchMessage(m PatchBrowser) bool {
+	return m.Type == EventLiveDom || m.Type == EventLiveBatch
+}
+
+// keptMessages returns the messages of m kept on a resync.
+func keptMessages(m PatchBrowser) []PatchBrowser {
+	if m.Type == EventLiveBatch {
+		kept := make([]PatchBrowser, 0)
+		for _, p := range m.Patches {
+			kept = append(kept, keptMessages(p)...)
+		}
+		return kept
+	}
+
+	if m.Type == EventLiveDom {
+		return nil
+	}
+
+	return []PatchBrowser{m}
+}
@@ -0,0 +1,127 @@
+package golive
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSession_QueueMessageOrder(t *testing.T) {
+	s := NewSession()
+
+	for i := 0; i < DefaultQueueSize; i++ {
+		s.QueueMessage(PatchBrowser{Type: EventLiveDom, Message: strconv.Itoa(i)})
+	}
+
+	for i := 0; i < DefaultQueueSize; i++ {
+		if msg := <-s.OutChannel; msg.Message != strconv.Itoa(i) {
+			t.Fatalf("message %d out of order: %s", i, msg.Message)
+		}
+	}
+
+	if stats := s.QueueStats(); stats.MaxDepth != DefaultQueueSize || stats.Depth != 0 || stats.Dropped != 0 {
+		t.Error("unexpected stats", stats)
+	}
+}
+
+func TestSession_QueueDisconnect(t *testing.T) {
+	s := NewSession()
+	s.setQueue(2, QueueDisconnect)
+
+	for i := 0; i < 3; i++ {
+		s.QueueMessage(PatchBrowser{Type: EventLiveDom})
+	}
+
+	select {
+	case <-s.queue.overflow:
+	default:
+		t.Error("connection not asked to close")
+	}
+
+	if stats := s.QueueStats(); stats.Capacity != 2 || stats.Depth != 2 || stats.Dropped != 1 || stats.Overflows != 1 {
+		t.Error("unexpected stats", stats)
+	}
+}
+
+func TestSession_QueueBlock(t *testing.T) {
+	s := NewSession()
+	s.setQueue(1, QueueBlock)
+
+	s.QueueMessage(PatchBrowser{Message: "first"})
+
+	sent := make(chan struct{})
+	go func() {
+		s.QueueMessage(PatchBrowser{Message: "second"})
+		s.QueueMessage(PatchBrowser{Message: "third"})
+		close(sent)
+	}()
+
+	if msg := <-s.OutChannel; msg.Message != "first" {
+		t.Fatal("unexpected message", msg)
+	}
+
+	if msg := <-s.OutChannel; msg.Message != "second" {
+		t.Fatal("unexpected message", msg)
+	}
+
+	// Closing the queue releases the blocked sender
+	s.closeQueue()
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sender still blocked")
+	}
+}
+
+func TestSession_QueueDropResync(t *testing.T) {
+	server := NewServer()
+	server.QueueSize = 4
+
+	lc := NewLiveComponent("Item", &batchItem{})
+	lc.log = server.Log
+
+	lr, err := server.HandleFirstRequest(lc, PageContent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := server.Wire.GetSession(lr.Session)
+
+	for i := 0; i < 4; i++ {
+		s.QueueMessage(PatchBrowser{Type: EventLiveDom})
+	}
+
+	s.QueueMessage(PatchBrowser{Type: EventLiveConnectElement, ComponentID: "marker"})
+
+	replaced := false
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case msg := <-s.OutChannel:
+			if msg.Type == EventLiveDom && len(msg.Instructions) == 0 {
+				t.Fatal("dropped patch sent")
+			}
+
+			if msg.Type == EventLiveDom && msg.Instructions[0].Type == Replace.toString() {
+				replaced = true
+			}
+
+			if msg.ComponentID != "marker" {
+				continue
+			}
+
+			if !replaced {
+				t.Fatal("component connected before the full render")
+			}
+
+			if stats := s.QueueStats(); stats.Dropped < 4 || stats.Overflows == 0 {
+				t.Error("unexpected stats", stats)
+			}
+			return
+		case <-timeout:
+			t.Fatal("page not resynced")
+		}
+	}
+}
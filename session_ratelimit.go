@@ -0,0 +1,107 @@
+package golive
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrRateLimited = errors.New("browser events rate limited")
+
+// Default limits of the browser events of a session, the client modifiers
+// go-live-debounce and go-live-throttle keep pages far below them.
+const (
+	DefaultEventRate  = 50
+	DefaultEventBurst = 100
+)
+
+// eventLimiter is a token bucket limiting the events of a session.
+type eventLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// resyncing is set while the resync of the dropped events is pending
+	resyncing int32
+}
+
+func newEventLimiter(rate float64, burst int) *eventLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &eventLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// allow takes a token, reporting if there was one.
+func (l *eventLimiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
+
+// setEventLimit limits the browser events of the session to rate per
+// second, allowing bursts of burst events. A zero rate disables the limit.
+func (s *Session) setEventLimit(rate float64, burst int) {
+	if rate <= 0 {
+		s.limiter = nil
+		return
+	}
+
+	s.limiter = newEventLimiter(rate, burst)
+}
+
+// allowEvent reports if the browser event is within the limit. Upload
+// chunks are limited by the size of their uploads instead, and
+// navigations are never dropped, the browser already changed its url.
+func (s *Session) allowEvent(message BrowserEvent) bool {
+	if s.limiter == nil || message.Name == EventLiveUploadChunk || message.Name == EventLiveNavigate {
+		return true
+	}
+
+	return s.limiter.allow(time.Now())
+}
+
+// dropEvent tells the browser its event was dropped and renders the page
+// again, the browser shows the inputs it sent but the session did not
+// get. The events dropped until the resync runs do not start another.
+func (s *Session) dropEvent(message BrowserEvent) {
+	if !atomic.CompareAndSwapInt32(&s.limiter.resyncing, 0, 1) {
+		return
+	}
+
+	s.QueueMessage(PatchBrowser{
+		ComponentID: message.ComponentID,
+		Type:        EventLiveError,
+		Message:     LiveErrorRateLimited,
+	})
+
+	s.LivePage.Emit(PageResync, nil)
+}
+
+// resyncingEvents ends the wait of the events dropped before the resync.
+func (s *Session) resyncingEvents() {
+	if s.limiter != nil {
+		atomic.StoreInt32(&s.limiter.resyncing, 0)
+	}
+}
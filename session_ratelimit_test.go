@@ -0,0 +1,85 @@
+package golive
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventLimiter_Allow(t *testing.T) {
+	limiter := newEventLimiter(10, 2)
+	now := limiter.last
+
+	if !limiter.allow(now) || !limiter.allow(now) {
+		t.Fatal("burst not allowed")
+	}
+
+	if limiter.allow(now) {
+		t.Error("event above the burst allowed")
+	}
+
+	if !limiter.allow(now.Add(100 * time.Millisecond)) {
+		t.Error("event not allowed after refill")
+	}
+}
+
+func TestSession_IngestMessageRateLimited(t *testing.T) {
+	server := NewServer()
+	server.Log = func(int, string, map[string]interface{}) {}
+	server.EventRate = 0.001
+	server.EventBurst = 2
+
+	lc := NewLiveComponent("Item", &batchItem{})
+	lc.log = server.Log
+
+	if _, err := server.HandleFirstRequest(lc, PageContent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var session *Session
+	server.Wire.Sessions.Range(func(_ string, s *Session) bool {
+		session = s
+		return false
+	})
+
+	event := BrowserEvent{Name: EventLiveMethod, ComponentID: lc.Name, MethodName: "Missing"}
+
+	for i := 0; i < 2; i++ {
+		if err := session.IngestMessage(event); errors.Is(err, ErrRateLimited) {
+			t.Fatal("event within the burst rate limited")
+		}
+	}
+
+	if err := session.IngestMessage(event); !errors.Is(err, ErrRateLimited) {
+		t.Error("event above the limit not rate limited", err)
+	}
+
+	// The browser is told, and gets the page again
+	for received := false; !received; {
+		select {
+		case msg := <-session.OutChannel:
+			received = msg.Type == EventLiveError && msg.Message == LiveErrorRateLimited
+		case <-time.After(5 * time.Second):
+			t.Fatal("rate limited error not sent")
+		}
+	}
+
+	select {
+	case msg := <-session.OutChannel:
+		if msg.Type != EventLiveDom {
+			t.Error("expecting resync patch, received", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("page not resynced after dropped event")
+	}
+
+	navigate := BrowserEvent{Name: EventLiveNavigate, URL: "/nowhere"}
+	if err := session.IngestMessage(navigate); errors.Is(err, ErrRateLimited) {
+		t.Error("navigation rate limited")
+	}
+
+	chunk := BrowserEvent{Name: EventLiveUploadChunk, ComponentID: lc.Name}
+	if err := session.IngestMessage(chunk); errors.Is(err, ErrRateLimited) {
+		t.Error("upload chunk rate limited")
+	}
+}
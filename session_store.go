@@ -0,0 +1,183 @@
+package golive
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSessionNewTTL is how long a session waits for its connection
+// before being evicted.
+const DefaultSessionNewTTL = 1 * time.Minute
+
+// SessionStore keeps the sessions of a LiveWire. Implementations must be
+// safe for concurrent use, sessions are accessed from HTTP and connection
+// goroutines at the same time.
+type SessionStore interface {
+	Get(key string) *Session
+	Set(key string, s *Session)
+	Delete(key string)
+
+	// Len returns the number of stored sessions
+	Len() int
+
+	// Range calls f for each stored session until f returns false
+	Range(f func(key string, s *Session) bool)
+}
+
+type memorySessionEntry struct {
+	session   *Session
+	createdAt time.Time
+}
+
+// MemorySessionStore is the default in-memory SessionStore. Sessions that
+// stay in SessionNew longer than TTL are evicted, when sessions are stored
+// and by the janitor started with StartJanitor.
+type MemorySessionStore struct {
+	// TTL of sessions whose connection was never opened, zero disables
+	// the eviction
+	TTL time.Duration
+
+	// OnEvict is called for each evicted session
+	OnEvict func(key string, s *Session)
+
+	mu           sync.RWMutex
+	sessions     map[string]memorySessionEntry
+	lastEviction time.Time
+
+	// janitor is closed to stop the janitor
+	janitor chan struct{}
+}
+
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{
+		TTL:          ttl,
+		sessions:     make(map[string]memorySessionEntry),
+		lastEviction: time.Now(),
+	}
+}
+
+func (m *MemorySessionStore) Get(key string) *Session {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.sessions[key].session
+}
+
+// Set stores the session, evicting the expired ones from time to time.
+func (m *MemorySessionStore) Set(key string, s *Session) {
+	m.mu.Lock()
+	m.sessions[key] = memorySessionEntry{
+		session:   s,
+		createdAt: time.Now(),
+	}
+	shouldEvict := m.TTL > 0 && time.Since(m.lastEviction) > m.TTL/4
+	m.mu.Unlock()
+
+	if shouldEvict {
+		m.EvictExpired()
+	}
+}
+
+func (m *MemorySessionStore) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, key)
+}
+
+func (m *MemorySessionStore) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.sessions)
+}
+
+// Range iterates over a snapshot of the sessions, so f is free to
+// call other store methods.
+func (m *MemorySessionStore) Range(f func(key string, s *Session) bool) {
+	m.mu.RLock()
+	snapshot := make(map[string]*Session, len(m.sessions))
+	for key, entry := range m.sessions {
+		snapshot[key] = entry.session
+	}
+	m.mu.RUnlock()
+
+	for key, s := range snapshot {
+		if !f(key, s) {
+			return
+		}
+	}
+}
+
+// StartJanitor evicts the expired sessions every TTL/4 in background, so
+// abandoned sessions are evicted while no session is stored. Close stops
+// it.
+func (m *MemorySessionStore) StartJanitor() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.TTL <= 0 || m.janitor != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	m.janitor = stop
+
+	go func() {
+		ticker := time.NewTicker(m.TTL / 4)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.EvictExpired()
+			}
+		}
+	}()
+}
+
+// Close stops the janitor.
+func (m *MemorySessionStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.janitor != nil {
+		close(m.janitor)
+		m.janitor = nil
+	}
+
+	return nil
+}
+
+// EvictExpired removes the sessions that stayed in SessionNew longer than
+// TTL and returns how many were evicted.
+func (m *MemorySessionStore) EvictExpired() int {
+	if m.TTL <= 0 {
+		return 0
+	}
+
+	m.mu.Lock()
+	now := time.Now()
+	m.lastEviction = now
+
+	evicted := make(map[string]*Session)
+	for key, entry := range m.sessions {
+		if entry.session.GetStatus() != SessionNew || now.Sub(entry.createdAt) < m.TTL {
+			continue
+		}
+
+		evicted[key] = entry.session
+		delete(m.sessions, key)
+	}
+	m.mu.Unlock()
+
+	if m.OnEvict != nil {
+		for key, s := range evicted {
+			m.OnEvict(key, s)
+		}
+	}
+
+	return len(evicted)
+}
@@ -0,0 +1,98 @@
+package golive
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemorySessionStore_EvictExpired(t *testing.T) {
+	store := NewMemorySessionStore(time.Millisecond)
+
+	evicted := make([]string, 0)
+	store.OnEvict = func(key string, _ *Session) {
+		evicted = append(evicted, key)
+	}
+
+	open := NewSession()
+	open.Status = SessionOpen
+
+	store.Set("new", NewSession())
+	store.Set("open", open)
+
+	time.Sleep(2 * time.Millisecond)
+
+	if n := store.EvictExpired(); n != 1 {
+		t.Error("expecting to evict 1 session, evicted", n)
+	}
+
+	if len(evicted) != 1 || evicted[0] != "new" {
+		t.Error("OnEvict not called with the new session", evicted)
+	}
+
+	if store.Get("new") != nil || store.Get("open") != open {
+		t.Error("only the session never opened should be evicted")
+	}
+}
+
+func TestMemorySessionStore_Concurrent(t *testing.T) {
+	store := NewMemorySessionStore(0)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i)
+			store.Set(key, NewSession())
+			_ = store.Get(key)
+			store.Range(func(_ string, _ *Session) bool { return true })
+			if i%2 == 0 {
+				store.Delete(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if store.Len() != 25 {
+		t.Error("expecting 25 sessions, found", store.Len())
+	}
+
+	count := 0
+	store.Range(func(_ string, _ *Session) bool {
+		count++
+		return count < 10
+	})
+
+	if count != 10 {
+		t.Error("range should stop when f returns false, iterated", count)
+	}
+}
+
+func TestMemorySessionStore_Janitor(t *testing.T) {
+	store := NewMemorySessionStore(4 * time.Millisecond)
+	store.StartJanitor()
+	store.StartJanitor()
+
+	store.Set("new", NewSession())
+
+	// Evicted without storing other sessions
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned session not evicted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_ = store.Close()
+	_ = store.Close()
+
+	store.Set("new", NewSession())
+	time.Sleep(20 * time.Millisecond)
+
+	if store.Get("new") == nil {
+		t.Error("session evicted after closing the store")
+	}
+}
@@ -0,0 +1,166 @@
+package golive
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrServerShutdown = errors.New("server is shutting down")
+
+// Shutdown ends every session of the server. The components are killed,
+// running their BeforeUnmount hooks, and the browsers are told about the
+// shutdown before their connections are closed, once the queued messages
+// were sent. New requests and connections are refused.
+//
+// Shutdown returns when the goroutines of the sessions and connections
+// exited, or the error of ctx when it is done before.
+func (s *LiveServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shuttingDown = true
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+
+	s.Wire.Sessions.Range(func(key string, session *Session) bool {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := session.shutdown(ctx); err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+			}
+
+			s.Wire.DeleteSession(key)
+		}()
+
+		return true
+	})
+
+	wg.Wait()
+
+	if err := s.Wire.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	connectionsDone := make(chan struct{})
+	go func() {
+		s.connections.Wait()
+		close(connectionsDone)
+	}()
+
+	select {
+	case <-connectionsDone:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return firstErr
+}
+
+// acceptConnection registers a connection, unless the server is shutting
+// down.
+func (s *LiveServer) acceptConnection() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown {
+		return false
+	}
+
+	s.connections.Add(1)
+	return true
+}
+
+// createSession creates a session, unless the server is shutting down.
+// The session is stored under the lock of Shutdown, so the sessions it
+// ends include it.
+func (s *LiveServer) createSession() (string, *Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown {
+		return "", nil, ErrServerShutdown
+	}
+
+	return s.Wire.CreateSession()
+}
+
+// shutdown ends the session: its page is closed, the browser is told
+// about the shutdown and its connection is closed.
+func (s *Session) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.Status = SessionExpired
+	if s.expire != nil {
+		s.expire.Stop()
+		s.expire = nil
+	}
+	s.mu.Unlock()
+
+	err := s.stop(ctx)
+
+	notified := make(chan struct{})
+	go func() {
+		defer close(notified)
+
+		s.QueueMessage(PatchBrowser{
+			Type:    EventLiveError,
+			Message: LiveErrorServerShutdown,
+		})
+
+		s.hangUp()
+	}()
+
+	select {
+	case <-notified:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+
+	// Releases the notification when it is blocked by QueueBlock
+	s.closeQueue()
+
+	return err
+}
+
+// stop kills the page of the session, waiting for its goroutines to exit.
+func (s *Session) stop(ctx context.Context) error {
+	s.mu.Lock()
+	lp := s.LivePage
+	s.mu.Unlock()
+
+	if lp == nil {
+		return nil
+	}
+
+	select {
+	case <-lp.done:
+		return nil
+	default:
+	}
+
+	lp.Emit(PageClose, nil)
+
+	select {
+	case <-lp.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hangUp asks the connection to send the queued messages and close.
+func (s *Session) hangUp() {
+	s.hangupOnce.Do(func() {
+		close(s.hangup)
+	})
+}
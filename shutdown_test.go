@@ -0,0 +1,261 @@
+package golive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type shutdownItem struct {
+	LiveComponentWrapper
+	unmounts *int32
+}
+
+func (i *shutdownItem) BeforeUnmount(_ *LiveComponent) {
+	atomic.AddInt32(i.unmounts, 1)
+}
+
+func (i *shutdownItem) TemplateHandler(_ *LiveComponent) string {
+	return `<p>item</p>`
+}
+
+type shutdownList struct {
+	LiveComponentWrapper
+	unmounts *int32
+	Item     *LiveComponent
+}
+
+func (l *shutdownList) BeforeUnmount(_ *LiveComponent) {
+	atomic.AddInt32(l.unmounts, 1)
+}
+
+func (l *shutdownList) TemplateHandler(_ *LiveComponent) string {
+	return `<div>{{ render .Item }}</div>`
+}
+
+// testConnection is a LiveConnection keeping the written messages.
+type testConnection struct {
+	mu       sync.Mutex
+	messages []PatchBrowser
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newTestConnection() *testConnection {
+	return &testConnection{closed: make(chan struct{})}
+}
+
+func (c *testConnection) ReadJSON(_ interface{}) error {
+	<-c.closed
+	return errors.New("connection closed")
+}
+
+func (c *testConnection) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, v.(PatchBrowser))
+	return nil
+}
+
+func (c *testConnection) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *testConnection) SetCloseHandler(_ func(code int, text string) error) {}
+
+func (c *testConnection) last() PatchBrowser {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.messages) == 0 {
+		return PatchBrowser{}
+	}
+
+	return c.messages[len(c.messages)-1]
+}
+
+// waitGoroutines waits until the number of goroutines is back to n.
+func waitGoroutines(t *testing.T, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines leaked\n%s", runtime.NumGoroutine()-n, buf[:runtime.Stack(buf, true)])
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLiveServer_Shutdown(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	server := NewServer()
+	server.Log = func(int, string, map[string]interface{}) {}
+
+	var unmounts int32
+	connections := make([]*testConnection, 0)
+	handled := make(chan struct{}, 3)
+
+	for i := 0; i < 3; i++ {
+		lc := NewLiveComponent("List", &shutdownList{
+			unmounts: &unmounts,
+			Item:     NewLiveComponent("Item", &shutdownItem{unmounts: &unmounts}),
+		})
+		lc.log = server.Log
+
+		lr, err := server.HandleFirstRequest(lc, PageContent{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// One of the sessions is never connected
+		if i == 0 {
+			continue
+		}
+
+		conn := newTestConnection()
+		connections = append(connections, conn)
+
+		go func() {
+			server.HandleConnection(lr.Session, conn)
+			handled <- struct{}{}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if unmounts != 6 {
+		t.Error("BeforeUnmount not called for every component", unmounts)
+	}
+
+	for _, conn := range connections {
+		<-handled
+
+		if msg := conn.last(); msg.Type != EventLiveError || msg.Message != LiveErrorServerShutdown {
+			t.Error("browser not told about the shutdown", msg)
+		}
+	}
+
+	if server.Wire.Sessions.Len() != 0 {
+		t.Error("sessions kept after shutdown", server.Wire.Sessions.Len())
+	}
+
+	if _, err := server.HandleFirstRequest(NewLiveComponent("Item", &shutdownItem{unmounts: &unmounts}), PageContent{}); !errors.Is(err, ErrServerShutdown) {
+		t.Error("request accepted after shutdown", err)
+	}
+
+	waitGoroutines(t, before)
+}
+
+func TestLiveServer_ShutdownWebsocket(t *testing.T) {
+	liveServer, server := newHTTPTestServer(t)
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	conn := dialHTTPTestServer(t, server, res.Cookies())
+
+	readPatchOfType(t, conn, EventLiveConnectElement)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := liveServer.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := readPatchOfType(t, conn, EventLiveError); msg.Message != LiveErrorServerShutdown {
+		t.Error("unexpected error message", msg)
+	}
+
+	var msg PatchBrowser
+	if err := conn.ReadJSON(&msg); err == nil {
+		t.Error("connection not closed", msg)
+	}
+}
+
+func TestLiveServer_ShutdownWhileCreating(t *testing.T) {
+	server := NewServer()
+	server.Log = func(int, string, map[string]interface{}) {}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				lc := NewLiveComponent("Item", &batchItem{})
+				lc.log = server.Log
+
+				if _, err := server.HandleFirstRequest(lc, PageContent{}); errors.Is(err, ErrServerShutdown) {
+					return
+				}
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+
+	if n := server.Wire.Sessions.Len(); n != 0 {
+		t.Error("sessions created while shutting down were not ended", n)
+	}
+}
+
+func TestWire_EvictionDoesNotWait(t *testing.T) {
+	store := NewMemorySessionStore(time.Millisecond)
+	store.OnEvict = killSession
+
+	session := NewSession()
+	store.Set("stuck", session)
+
+	// A page whose events are never read, as a stuck session
+	session.LivePage = NewLivePage(NewLiveComponent("Item", &batchItem{}))
+	defer session.LivePage.Close()
+
+	time.Sleep(2 * time.Millisecond)
+
+	evicted := make(chan int)
+	go func() {
+		evicted <- store.EvictExpired()
+	}()
+
+	select {
+	case n := <-evicted:
+		if n != 1 {
+			t.Error("expecting to evict 1 session, evicted", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("eviction waiting for the session page")
+	}
+}
@@ -1,27 +1,78 @@
 package golive
 
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// killSessionTimeout bounds how long killing a session waits for its page.
+const killSessionTimeout = 10 * time.Second
+
 type LiveWire struct {
-	Sessions WireSessions
+	Sessions SessionStore
 }
 
+// Deprecated: WireSessions was the type of LiveWire.Sessions, which is a
+// SessionStore now.
 type WireSessions map[string]*Session
 
+// NewWire creates a wire backed by a MemorySessionStore, running its
+// janitor until the wire is closed.
 func NewWire() *LiveWire {
+	store := NewMemorySessionStore(DefaultSessionNewTTL)
+	store.OnEvict = killSession
+	store.StartJanitor()
+	return NewWireWithStore(store)
+}
+
+// Close stops the background work of the session store, when it has any.
+func (w *LiveWire) Close() error {
+	if closer, ok := w.Sessions.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// NewWireWithStore creates a wire keeping the sessions in store.
+func NewWireWithStore(store SessionStore) *LiveWire {
 	return &LiveWire{
-		Sessions: make(WireSessions),
+		Sessions: store,
 	}
 }
 
 func (w *LiveWire) GetSession(s string) *Session {
-	return w.Sessions[s]
+	return w.Sessions.Get(s)
 }
+
 func (w *LiveWire) DeleteSession(s string) {
-	delete(w.Sessions, s)
+	w.Sessions.Delete(s)
 }
 
 func (w *LiveWire) CreateSession() (string, *Session, error) {
-	key, _ := GenerateRandomString(48)
+	key, err := GenerateRandomString(48)
+	if err != nil {
+		return "", nil, err
+	}
+
 	s := NewSession()
-	w.Sessions[key] = s
+	w.Sessions.Set(key, s)
 	return key, s, nil
 }
+
+// killSession kills the page of a session that will not be used anymore.
+// It does not wait for the page, the caller may be a request evicting
+// sessions it is not related to.
+func killSession(key string, s *Session) {
+	s.closeQueue()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), killSessionTimeout)
+		defer cancel()
+
+		if err := s.stop(ctx); err != nil && s.log != nil {
+			s.log(LogError, "kill session page", logEx{"error": err, "session": key})
+		}
+	}()
+}